@@ -0,0 +1,342 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v66/github"
+)
+
+// triager carries out actions on notifications, keeping count of what it did
+// and recording it in the local history.
+type triager struct {
+	client    *github.Client
+	state     *State
+	snoozeFor time.Duration
+	dryRun    bool
+	tally     session
+	undoStack []undoable
+	// safeRepos, if set, are the only repos we'll change anything in.
+	safeRepos []string
+	// failedMarks are the notifications we couldn't mark read, one entry per
+	// thread ID, for retryFailed.
+	failedMarks []failedMark
+	// batchSize and inbox are for readAll; see batchread.go.
+	batchSize int
+	inbox     []*github.Notification
+	// throttle is the least time between marking requests (-throttle), and
+	// lastMark when the last one went out.
+	throttle time.Duration
+	lastMark time.Time
+}
+
+// maxUndo is how many actions can be undone.
+const maxUndo = 5
+
+// undoable is a manual action that can be undone.
+type undoable struct {
+	n      *github.Notification
+	action string
+}
+
+func (t *triager) pushUndo(n *github.Notification, action string) {
+	t.undoStack = append(t.undoStack, undoable{n, action})
+	if len(t.undoStack) > maxUndo {
+		t.undoStack = t.undoStack[1:]
+	}
+}
+
+// lastUndoable returns the notification undo would act on, if any.
+func (t *triager) lastUndoable() *github.Notification {
+	if len(t.undoStack) == 0 {
+		return nil
+	}
+	return t.undoStack[len(t.undoStack)-1].n
+}
+
+// undo reverts the most recent manual action as far as GitHub allows and
+// returns the notification so it can be shown again. There's no API to mark
+// a thread unread, so read and archived threads only come back locally.
+func (t *triager) undo(ctx context.Context) *github.Notification {
+	if len(t.undoStack) == 0 {
+		fmt.Println("🤷 Nothing to undo.")
+		return nil
+	}
+	u := t.undoStack[len(t.undoStack)-1]
+	t.undoStack = t.undoStack[:len(t.undoStack)-1]
+
+	switch u.action {
+	case "snoozed":
+		delete(t.state.Snoozed, u.n.GetID())
+		t.tally.snoozed--
+	case "unsubscribed":
+		if err := reopenThread(ctx, t.client, u.n.GetID()); err != nil {
+			log.Printf("⚠️  Failed to undo: %v\n", err)
+			return nil
+		}
+		t.tally.unsubscribed--
+	case "read":
+		t.tally.read--
+	case "archived":
+		t.tally.archived--
+	}
+	fmt.Printf("↩️  Undid %s: %s\n", u.action, u.n.GetSubject().GetTitle())
+	if u.action == "read" || u.action == "archived" || u.action == "unsubscribed" {
+		fmt.Println("   (GitHub can't mark threads unread through the API, so it stays read there.)")
+	}
+	t.state.record(u.n, "undone")
+	return u.n
+}
+
+// safe reports whether n is in safe_repos, or there's no such list.
+func (t *triager) safe(n *github.Notification) bool {
+	return inSafeRepos(t.safeRepos, n)
+}
+
+func inSafeRepos(safeRepos []string, n *github.Notification) bool {
+	repo := n.GetRepository().GetFullName()
+	return len(safeRepos) == 0 || slices.ContainsFunc(safeRepos, func(r string) bool { return strings.EqualFold(r, repo) })
+}
+
+// allowed is safe but also reports and counts n as skipped if it isn't.
+func (t *triager) allowed(n *github.Notification) bool {
+	if t.safe(n) {
+		return true
+	}
+	fmt.Printf("⏭️  Skipped (not in safe_repos): %s\n", n.GetSubject().GetTitle())
+	t.tally.skipped++
+	return false
+}
+
+// The actions below report whether they succeeded; failures are logged and
+// counted. In dry-run mode they only say what they would have done. Those
+// that change the thread on GitHub refuse to outside safe_repos.
+
+func (t *triager) markRead(ctx context.Context, n *github.Notification) bool {
+	if !t.allowed(n) {
+		return false
+	}
+	if err := t.apiMarkRead(ctx, n); err != nil {
+		log.Printf("⚠️  Failed to mark as read: %v\n", err)
+		t.markFailed(n, "read", err)
+		return false
+	}
+	t.say("✅ Marked as read.")
+	t.tally.read++
+	t.state.record(n, "read")
+	t.pushUndo(n, "read")
+	return true
+}
+
+// autoRead is markRead for notifications handled by a rule rather than by
+// the user. It can't be undone.
+func (t *triager) autoRead(ctx context.Context, n *github.Notification) bool {
+	if !t.autoReadQuietly(ctx, n) {
+		return false
+	}
+	t.say("✅ Marked as read.")
+	return true
+}
+
+func (t *triager) autoReadQuietly(ctx context.Context, n *github.Notification) bool {
+	if !t.allowed(n) {
+		return false
+	}
+	if err := t.apiMarkRead(ctx, n); err != nil {
+		log.Printf("⚠️  Failed to mark %q as read: %v\n", n.GetSubject().GetTitle(), err)
+		t.markFailed(n, "auto-read", err)
+		return false
+	}
+	t.tally.autoRead++
+	t.state.record(n, "auto-read")
+	return true
+}
+
+func (t *triager) apiMarkRead(ctx context.Context, n *github.Notification) error {
+	if t.dryRun {
+		return nil
+	}
+	if err := t.pace(ctx); err != nil {
+		return err
+	}
+	_, err := t.client.Activity.MarkThreadRead(ctx, n.GetID())
+	return err
+}
+
+// pace waits out whatever's left of -throttle since the last marking
+// request, so a big bulk action doesn't trip GitHub's secondary rate limits.
+// Time spent at the prompt counts, so interactive use rarely waits.
+func (t *triager) pace(ctx context.Context) error {
+	if t.throttle <= 0 {
+		return nil
+	}
+	if wait := time.Until(t.lastMark.Add(t.throttle)); wait > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+	t.lastMark = time.Now()
+	return nil
+}
+
+// bulkRead marks n read without any output of its own, for bulk actions
+// that report once at the end. It can't be undone.
+func (t *triager) bulkRead(ctx context.Context, n *github.Notification) bool {
+	if !t.allowed(n) {
+		return false
+	}
+	if err := t.apiMarkRead(ctx, n); err != nil {
+		log.Printf("⚠️  Failed to mark %q as read: %v\n", n.GetSubject().GetTitle(), err)
+		t.markFailed(n, "read", err)
+		return false
+	}
+	t.tally.read++
+	t.state.record(n, "read")
+	return true
+}
+
+// say prints an outcome, flagged as hypothetical in dry-run mode.
+func (t *triager) say(msg string) {
+	if t.dryRun {
+		fmt.Println("🧪 (dry run) " + msg)
+		return
+	}
+	fmt.Println(msg)
+}
+
+// archive marks the thread done ("Done" in the GitHub UI). Unlike read
+// threads, done threads don't come back when the subject is updated.
+func (t *triager) archive(ctx context.Context, n *github.Notification) bool {
+	if !t.allowed(n) {
+		return false
+	}
+	id, err := strconv.ParseInt(n.GetID(), 10, 64)
+	if err != nil {
+		log.Printf("⚠️  Failed to archive: bad thread id %q\n", n.GetID())
+		t.tally.failed++
+		return false
+	}
+	if !t.dryRun {
+		if _, err := t.client.Activity.MarkThreadDone(ctx, id); err != nil {
+			log.Printf("⚠️  Failed to archive: %v\n", err)
+			t.tally.failed++
+			return false
+		}
+	}
+	t.say("🗄️  Archived.")
+	t.tally.archived++
+	t.state.record(n, "archived")
+	t.pushUndo(n, "archived")
+	return true
+}
+
+// unsubscribe ignores the thread so it stops notifying, then marks it read.
+func (t *triager) unsubscribe(ctx context.Context, n *github.Notification) bool {
+	if !t.allowed(n) {
+		return false
+	}
+	if !t.dryRun {
+		_, _, err := t.client.Activity.SetThreadSubscription(ctx, n.GetID(), &github.Subscription{Ignored: github.Bool(true)})
+		if err != nil {
+			log.Printf("⚠️  Failed to unsubscribe: %v\n", err)
+			t.tally.failed++
+			return false
+		}
+	}
+	t.say("🔕 Unsubscribed.")
+	t.tally.unsubscribed++
+	t.state.record(n, "unsubscribed")
+	t.pushUndo(n, "unsubscribed")
+	if err := t.apiMarkRead(ctx, n); err != nil {
+		log.Printf("⚠️  Failed to mark as read: %v\n", err)
+		// The unsubscribe is already recorded, so a retry has nothing to add.
+		t.markFailed(n, "", err)
+	}
+	return true
+}
+
+// snooze hides the notification locally until the snooze runs out. It stays
+// unread on GitHub.
+func (t *triager) snooze(n *github.Notification) {
+	until := time.Now().Add(t.snoozeFor)
+	t.state.snooze(n.GetID(), until)
+	t.say(fmt.Sprintf("😴 Snoozed until %s.", until.Format("Mon Jan 2 15:04")))
+	t.tally.snoozed++
+	t.state.record(n, "snoozed")
+	t.pushUndo(n, "snoozed")
+}
+
+// skip leaves the notification alone. why is empty for a manual skip.
+func (t *triager) skip(n *github.Notification, why string) {
+	if why == "" {
+		fmt.Println("⏭️  Skipped.")
+	} else {
+		fmt.Printf("⏭️  Skipping: %s (%s)\n", n.GetSubject().GetTitle(), why)
+	}
+	t.tally.skipped++
+}
+
+// autoApproveBatch marks everything the settings auto-approve read up front,
+// prints one line summing it up by what approved it, and returns the
+// notifications that are left.
+func (t *triager) autoApproveBatch(ctx context.Context, notifications []*github.Notification, settings *Settings) []*github.Notification {
+	bySource := map[string]int{}
+	var sources []string
+	total := 0
+	now := time.Now()
+	rest := filterNotifications(notifications, func(n *github.Notification) bool {
+		d := settings.decide(n, now)
+		// Outside safe_repos it stays in the queue, to be shown and skipped.
+		if d.Action != "mark-read" || ctx.Err() != nil || !t.safe(n) {
+			return true
+		}
+		// Failures are logged and counted rather than retried in the loop.
+		if t.autoReadQuietly(ctx, n) {
+			if bySource[d.Source] == 0 {
+				sources = append(sources, d.Source)
+			}
+			bySource[d.Source]++
+			total++
+		}
+		return false
+	})
+	if total > 0 {
+		slices.SortStableFunc(sources, func(a, b string) int { return bySource[b] - bySource[a] })
+		var breakdown []string
+		for _, src := range sources {
+			breakdown = append(breakdown, fmt.Sprintf("%s: %d", src, bySource[src]))
+		}
+		t.say(fmt.Sprintf("⚡ Auto-approved %d notification(s) (%s).", total, strings.Join(breakdown, ", ")))
+	}
+	return rest
+}
+
+// session tallies what happened during a run for the closing summary.
+type session struct {
+	read, autoRead, archived, unsubscribed, snoozed, skipped, failed int
+	// missingRepos and archivedRepos are configured repos that need
+	// looking at, repeated at the end so they aren't lost in the scroll.
+	missingRepos, archivedRepos []string
+}
+
+func (s *session) print() {
+	fmt.Printf("📊 Read %d, auto-approved %d, archived %d, unsubscribed %d, snoozed %d, skipped %d",
+		s.read, s.autoRead, s.archived, s.unsubscribed, s.snoozed, s.skipped)
+	if s.failed > 0 {
+		fmt.Printf(", failed %d", s.failed)
+	}
+	fmt.Println(".")
+	for _, repo := range s.missingRepos {
+		fmt.Printf("⚠️  Skipped %s (not found; renamed or deleted?).\n", repo)
+	}
+	for _, repo := range s.archivedRepos {
+		fmt.Printf("🗄️  %s is archived.\n", repo)
+	}
+}
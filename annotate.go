@@ -0,0 +1,28 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// annotateThread saves note against the thread ID, or removes the note if
+// it's empty. Notes are local only; GitHub never sees them.
+func annotateThread(state *State, id, note string, dryRun bool) int {
+	prefix := ""
+	if dryRun {
+		// The state isn't saved in dry-run mode.
+		prefix = "🧪 (dry run) "
+	}
+	note = strings.TrimSpace(note)
+	if note == "" {
+		delete(state.Notes, id)
+		fmt.Printf("%s📝 Removed the note on %s.\n", prefix, id)
+		return exitOK
+	}
+	if state.Notes == nil {
+		state.Notes = map[string]string{}
+	}
+	state.Notes[id] = note
+	fmt.Printf("%s📝 Noted on %s: %s\n", prefix, id, note)
+	return exitOK
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+)
+
+// backupVersion is bumped if the backup format changes incompatibly.
+const backupVersion = 1
+
+// backupFile is what `backup` writes and `restore` reads: the local state
+// with a little about where it came from.
+type backupFile struct {
+	Version    int       `json:"version"`
+	ExportedAt time.Time `json:"exported_at"`
+	State      *State    `json:"state"`
+}
+
+// runBackup implements `backup`, which writes the local state to a JSON file
+// for moving to another machine or keeping safe.
+func runBackup(args []string) int {
+	fs := flag.NewFlagSet("backup", flag.ExitOnError)
+	output := fs.String("output", "", "file to write the backup to (required)")
+	fs.Parse(args)
+	if *output == "" {
+		fmt.Fprintln(os.Stderr, "usage: github-notification-manager backup -output backup.json")
+		return exitError
+	}
+
+	state, err := loadState()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ loading state: %v\n", err)
+		return exitError
+	}
+	data, err := json.MarshalIndent(backupFile{Version: backupVersion, ExportedAt: time.Now(), State: state}, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitError
+	}
+	if err := os.WriteFile(*output, data, 0o600); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ writing backup: %v\n", err)
+		return exitError
+	}
+	fmt.Printf("💾 Backed up %d history entries, %d snoozes and %d notes to %s.\n",
+		len(state.History), len(state.Snoozed), len(state.Notes), *output)
+	return exitOK
+}
+
+// runRestore implements `restore`, which merges a backup into the local
+// state rather than replacing it.
+func runRestore(args []string) int {
+	fs := flag.NewFlagSet("restore", flag.ExitOnError)
+	input := fs.String("input", "", "backup file to restore from (required)")
+	dryRun := fs.Bool("dry-run", false, "say what would be added without changing anything")
+	fs.Parse(args)
+	if *input == "" {
+		fmt.Fprintln(os.Stderr, "usage: github-notification-manager restore -input backup.json")
+		return exitError
+	}
+
+	data, err := os.ReadFile(*input)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ reading backup: %v\n", err)
+		return exitError
+	}
+	var backup backupFile
+	if err := json.Unmarshal(data, &backup); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %s: %v\n", *input, err)
+		return exitError
+	}
+	if backup.Version != backupVersion || backup.State == nil {
+		fmt.Fprintf(os.Stderr, "❌ %s isn't a backup this version can read\n", *input)
+		return exitError
+	}
+	state, err := loadState()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ loading state: %v\n", err)
+		return exitError
+	}
+
+	added := state.merge(backup.State)
+	summary := fmt.Sprintf("%d history entries, %d snoozes and %d notes", added.history, added.snoozes, added.notes)
+	if *dryRun {
+		fmt.Printf("🧪 (dry run) Would add %s.\n", summary)
+		return exitOK
+	}
+	if err := state.save(); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ saving state: %v\n", err)
+		return exitError
+	}
+	fmt.Printf("♻️  Restored %s.\n", summary)
+	return exitOK
+}
+
+// mergeCounts are how many of each thing merge added.
+type mergeCounts struct {
+	history, snoozes, notes int
+}
+
+// merge adds what other has and s doesn't. History entries are the same if
+// they're the same action on the same thread at the same time; a snooze in
+// both keeps the later wake time, and a note in both keeps s's.
+func (s *State) merge(other *State) mergeCounts {
+	var added mergeCounts
+
+	type historyKey struct {
+		id, action string
+		at         time.Time
+	}
+	seen := map[historyKey]bool{}
+	for _, e := range s.History {
+		seen[historyKey{e.ID, e.Action, e.At.UTC()}] = true
+	}
+	for _, e := range other.History {
+		if k := (historyKey{e.ID, e.Action, e.At.UTC()}); !seen[k] {
+			seen[k] = true
+			s.History = append(s.History, e)
+			added.history++
+		}
+	}
+	slices.SortStableFunc(s.History, func(a, b HistoryEntry) int { return a.At.Compare(b.At) })
+	if extra := len(s.History) - maxHistory; extra > 0 {
+		s.History = s.History[extra:]
+	}
+
+	for id, until := range other.Snoozed {
+		current, ok := s.Snoozed[id]
+		if ok && !until.After(current) {
+			continue
+		}
+		if !ok {
+			added.snoozes++
+		}
+		s.snooze(id, until)
+	}
+
+	for id, note := range other.Notes {
+		if _, ok := s.Notes[id]; ok {
+			continue
+		}
+		if s.Notes == nil {
+			s.Notes = map[string]string{}
+		}
+		s.Notes[id] = note
+		added.notes++
+	}
+
+	for _, day := range other.InboxZero {
+		if !slices.Contains(s.InboxZero, day) {
+			s.InboxZero = append(s.InboxZero, day)
+		}
+	}
+	slices.Sort(s.InboxZero)
+	return added
+}
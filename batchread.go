@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v66/github"
+)
+
+// readAll marks notifications read for the bulk modes and returns how many
+// API requests that took. With -batch-size above 1, a repo with at least that
+// many to mark is done in one request using GitHub's "mark everything in
+// this repo updated before last_read_at as read" endpoint. That endpoint
+// can't pick threads, so it's only used when the batch is every unread
+// notification t.inbox has for the repo; anything else, and any batch that
+// fails, is marked one at a time.
+func (t *triager) readAll(ctx context.Context, notifications []*github.Notification) int {
+	var repos []string
+	byRepo := map[string][]*github.Notification{}
+	for _, n := range notifications {
+		repo := n.GetRepository().GetFullName()
+		if byRepo[repo] == nil {
+			repos = append(repos, repo)
+		}
+		byRepo[repo] = append(byRepo[repo], n)
+	}
+	requests := 0
+	for _, repo := range repos {
+		batch := byRepo[repo]
+		if ctx.Err() != nil {
+			break
+		}
+		if t.batchSize > 1 && len(batch) >= t.batchSize && t.safe(batch[0]) && t.coversRepo(repo, batch) {
+			requests++
+			if t.batchRead(ctx, repo, batch) {
+				continue
+			}
+		}
+		for _, n := range batch {
+			if ctx.Err() != nil {
+				break
+			}
+			requests++
+			t.bulkRead(ctx, n)
+		}
+	}
+	return requests
+}
+
+// coversRepo reports whether batch holds every unread notification in the
+// inbox for repo, so marking the whole repo read won't catch anything else.
+func (t *triager) coversRepo(repo string, batch []*github.Notification) bool {
+	ids := map[string]bool{}
+	for _, n := range batch {
+		ids[n.GetID()] = true
+	}
+	for _, n := range t.inbox {
+		if n.GetRepository().GetFullName() == repo && n.GetUnread() && !ids[n.GetID()] {
+			return false
+		}
+	}
+	return true
+}
+
+// batchRead marks everything in repo up to the latest update in batch read
+// in one request. GitHub may answer 202 and finish the job in the
+// background, which go-github reports as an AcceptedError; that's a success
+// too.
+func (t *triager) batchRead(ctx context.Context, repo string, batch []*github.Notification) bool {
+	var latest time.Time
+	for _, n := range batch {
+		if u := n.GetUpdatedAt().Time; u.After(latest) {
+			latest = u
+		}
+	}
+	if !t.dryRun {
+		if err := t.pace(ctx); err != nil {
+			return false
+		}
+		owner, name, _ := strings.Cut(repo, "/")
+		if _, err := t.client.Activity.MarkRepositoryNotificationsRead(ctx, owner, name, github.Timestamp{Time: latest}); err != nil && !errors.As(err, new(*github.AcceptedError)) {
+			log.Printf("⚠️  Failed to mark %s read in one go, doing them one at a time: %v\n", repo, err)
+			return false
+		}
+	}
+	for _, n := range batch {
+		t.tally.read++
+		t.state.record(n, "read")
+	}
+	return true
+}
+
+// markAll implements -mark-all: it marks everything the run selected read,
+// after asking unless yes is set.
+func markAll(ctx context.Context, prompt *prompter, t *triager, notifications []*github.Notification, yes bool) {
+	if len(notifications) == 0 {
+		fmt.Println("👍 Nothing to mark read.")
+		return
+	}
+	if !yes {
+		text, err := prompt.ask(ctx, fmt.Sprintf("Mark all %d notification(s) read? [y/N]: ", len(notifications)))
+		if err != nil {
+			return
+		}
+		if text := strings.ToLower(text); text != "y" && text != "yes" {
+			fmt.Println("👍 Left everything alone.")
+			return
+		}
+	}
+	requests := t.readAll(ctx, notifications)
+	t.say(fmt.Sprintf("✅ Marked %d read in %d request(s).", t.tally.read, requests))
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-github/v66/github"
+	"github.com/lukemassa/github-notification-manager/testutil"
+)
+
+func TestReadAllBatchesRepo(t *testing.T) {
+	for _, accepted := range []bool{false, true} {
+		server := testutil.NewMockGitHubServer(t)
+		server.AcceptRepoReads = accepted
+		addNotifications(server, "lukemassa/example", "a", 3)
+		notifications, _, err := fetchAllUnread(context.Background(), server.Client(), []string{"lukemassa/example"}, fetchOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		tr := &triager{client: server.Client(), state: &State{}, batchSize: 2, inbox: notifications}
+		if requests := tr.readAll(context.Background(), notifications); requests != 1 {
+			t.Errorf("accepted=%v: took %d requests, want 1", accepted, requests)
+		}
+		if tr.tally.read != 3 || tr.tally.failed != 0 {
+			t.Errorf("accepted=%v: read %d, failed %d; want 3 and 0", accepted, tr.tally.read, tr.tally.failed)
+		}
+		for _, id := range notificationIDs(notifications) {
+			if !server.MarkReadCalled(id) {
+				t.Errorf("accepted=%v: %s not marked read", accepted, id)
+			}
+		}
+	}
+}
+
+func TestReadAllOneAtATimeWhenRepoNotCovered(t *testing.T) {
+	server := testutil.NewMockGitHubServer(t)
+	addNotifications(server, "lukemassa/example", "a", 3)
+	notifications, _, err := fetchAllUnread(context.Background(), server.Client(), []string{"lukemassa/example"}, fetchOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a3 is still in the inbox, so marking the whole repo would catch it.
+	tr := &triager{client: server.Client(), state: &State{}, batchSize: 2, inbox: notifications}
+	batch := []*github.Notification{notifications[0], notifications[1]}
+	if requests := tr.readAll(context.Background(), batch); requests != 2 {
+		t.Errorf("took %d requests, want 2", requests)
+	}
+	if server.MarkReadCalled("a3") {
+		t.Error("a3 marked read, want it left alone")
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/google/go-github/v66/github"
+)
+
+// maxBodyLines is how much of a body "b" prints; the rest is a click away.
+const maxBodyLines = 40
+
+// printBody prints the PR or issue description, raw markdown, so it can be
+// read without leaving the prompt. Bodies are fetched when first asked for
+// and kept for the rest of the session.
+func (d *display) printBody(ctx context.Context, client *github.Client, n *github.Notification) {
+	if !enrichable(n) {
+		fmt.Println("ℹ️  Only pull requests and issues have a body to show.")
+		return
+	}
+	url := n.GetSubject().GetURL()
+	body, ok := d.bodies[url]
+	if !ok {
+		req, err := client.NewRequest("GET", url, nil)
+		if err != nil {
+			log.Printf("⚠️  Failed to fetch the body: %v\n", err)
+			return
+		}
+		var raw struct {
+			Body string `json:"body"`
+		}
+		if _, err := client.Do(ctx, req, &raw); err != nil {
+			log.Printf("⚠️  Failed to fetch the body: %v\n", err)
+			return
+		}
+		body = strings.TrimSpace(strings.ReplaceAll(raw.Body, "\r\n", "\n"))
+		if d.bodies == nil {
+			d.bodies = map[string]string{}
+		}
+		d.bodies[url] = body
+	}
+	if body == "" {
+		fmt.Println("ℹ️  No description.")
+		return
+	}
+	lines := strings.Split(body, "\n")
+	for _, line := range lines[:min(len(lines), maxBodyLines)] {
+		fmt.Println("   " + line)
+	}
+	if more := len(lines) - maxBodyLines; more > 0 {
+		fmt.Printf("   … %d more line(s) at %s\n", more, uiURL(url))
+	}
+}
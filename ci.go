@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v66/github"
+)
+
+// CI states recorded in subjectDetails.CIState.
+const (
+	ciFailure = "failure"
+	ciPending = "pending"
+	ciSuccess = "success"
+)
+
+// ciNotification reports whether n is about a CI run rather than a PR or
+// issue. These have no subject URL to look up.
+func ciNotification(n *github.Notification) bool {
+	switch n.GetSubject().GetType() {
+	case "CheckSuite", "CheckRun", "WorkflowRun":
+		return true
+	}
+	return false
+}
+
+// ciFailed reports whether n is a failed CI run or a pull request whose
+// checks are failing. For PRs it needs enrichCI to have run.
+func (e *enricher) ciFailed(n *github.Notification) bool {
+	if ciNotification(n) {
+		// The notification doesn't link the run; its title is all we get,
+		// e.g. "CI workflow run failed for main branch".
+		return strings.Contains(strings.ToLower(n.GetSubject().GetTitle()), "failed")
+	}
+	if n.GetSubject().GetType() != "PullRequest" {
+		return false
+	}
+	d := e.cached(n)
+	return d != nil && d.CIState == ciFailure
+}
+
+// enrichCI looks up the check runs and commit statuses on each pull
+// request's head commit.
+func (e *enricher) enrichCI(ctx context.Context, notifications []*github.Notification) {
+	forEachEnrichable(ctx, notifications, func(n *github.Notification) error {
+		if n.GetSubject().GetType() != "PullRequest" {
+			return nil
+		}
+		d, err := e.details(ctx, n)
+		if err != nil {
+			return err
+		}
+		return e.ciState(ctx, n, d)
+	})
+}
+
+// ciState fills in d.CIState. Any failed check run or a failing combined
+// status counts as failure; we can't tell which checks are required without
+// admin access to the branch protection.
+func (e *enricher) ciState(ctx context.Context, n *github.Notification, d *subjectDetails) error {
+	// Pending checks are looked at again; they finish without the
+	// notification necessarily being updated.
+	e.mu.Lock()
+	done := (d.CIState != "" && d.CIState != ciPending) || d.HeadSHA == ""
+	e.mu.Unlock()
+	if done {
+		return nil
+	}
+	owner, repo := n.GetRepository().GetOwner().GetLogin(), n.GetRepository().GetName()
+	runs, _, err := e.client.Checks.ListCheckRunsForRef(ctx, owner, repo, d.HeadSHA, &github.ListCheckRunsOptions{
+		ListOptions: github.ListOptions{PerPage: 100},
+	})
+	if err != nil {
+		return fmt.Errorf("check runs: %w", err)
+	}
+	status, _, err := e.client.Repositories.GetCombinedStatus(ctx, owner, repo, d.HeadSHA, nil)
+	if err != nil {
+		return fmt.Errorf("commit status: %w", err)
+	}
+
+	state := ciSuccess
+	for _, run := range runs.CheckRuns {
+		switch {
+		case run.GetConclusion() == "failure" || run.GetConclusion() == "timed_out":
+			state = ciFailure
+		case run.GetStatus() != "completed" && state != ciFailure:
+			state = ciPending
+		}
+	}
+	// The combined status is "pending" when there are no statuses at all.
+	if status.GetTotalCount() > 0 {
+		switch status.GetState() {
+		case "failure", "error":
+			state = ciFailure
+		case "pending":
+			if state != ciFailure {
+				state = ciPending
+			}
+		}
+	}
+
+	e.mu.Lock()
+	d.CIState = state
+	e.dirty = true
+	e.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/google/go-github/v66/github"
+	"golang.org/x/oauth2"
+)
+
+// newClient builds a GitHub client for the settings' token. The returned
+// transport is what makes conditional requests, and holds the
+// -rate-limit-buffer guard when there is one.
+func newClient(ctx context.Context, settings *Settings) (*github.Client, *conditionalTransport, error) {
+	transport, err := newTransport(settings)
+	if err != nil {
+		return nil, nil, err
+	}
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, &http.Client{Transport: transport})
+	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: settings.Token})
+	tc := oauth2.NewClient(ctx, ts)
+	ct := &conditionalTransport{base: tc.Transport}
+	if settings.RateLimitBuffer > 0 {
+		ct.guard = &rateLimitGuard{base: tc.Transport, buffer: settings.RateLimitBuffer}
+		ct.base = ct.guard
+	}
+	tc.Transport = ct
+	return github.NewClient(tc), ct, nil
+}
+
+// newTransport builds the HTTP transport explicitly rather than relying on
+// http.DefaultTransport, so corporate proxies and CAs can be configured.
+// Proxies come from HTTPS_PROXY/HTTP_PROXY/NO_PROXY.
+func newTransport(settings *Settings) (*http.Transport, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if settings.CACert != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		pem, err := os.ReadFile(settings.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("reading -ca-cert: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("-ca-cert %s: no PEM certificates found", settings.CACert)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if settings.Insecure {
+		log.Println("🚨 -insecure: TLS certificates are NOT being verified. Anyone on the network path can read your token.")
+		tlsConfig.InsecureSkipVerify = true
+	}
+
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSClientConfig:       tlsConfig,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}, nil
+}
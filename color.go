@@ -0,0 +1,35 @@
+package main
+
+import (
+	"os"
+
+	"golang.org/x/term"
+)
+
+// reasonColor is the ANSI SGR code each reason is shown in, roughly by how
+// much it asks of you: review requests first, subscriptions last.
+var reasonColor = map[string]string{
+	"review_requested": "1;31",     // bold red
+	"mention":          "33",       // yellow
+	"assign":           "38;5;208", // orange
+	"author":           "34",       // blue
+	"comment":          "36",       // cyan
+	"subscribed":       "90",       // gray
+}
+
+// colorize wraps s in the SGR code, if there is one.
+func colorize(s, code string) string {
+	if code == "" {
+		return s
+	}
+	return "\x1b[" + code + "m" + s + "\x1b[0m"
+}
+
+// supportsColor reports whether stdout is a terminal that wants color,
+// honoring NO_COLOR (https://no-color.org).
+func supportsColor() bool {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+	return term.IsTerminal(int(os.Stdout.Fd())) && os.Getenv("TERM") != "dumb"
+}
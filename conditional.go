@@ -0,0 +1,44 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/google/go-github/v66/github"
+)
+
+// errNotModified is returned by fetchRepoUnread when GitHub answers a
+// conditional request with 304, meaning the listing it was given still
+// stands.
+var errNotModified = errors.New("notifications not modified")
+
+// listing is one repo's notifications as of the Last-Modified header GitHub
+// sent with them. A 304 only says nothing changed since that time, so the
+// header is only worth sending back with the notifications it came with.
+type listing struct {
+	LastModified  string                 `json:"last_modified,omitempty"`
+	Notifications []*github.Notification `json:"notifications"`
+}
+
+// ifModifiedSinceKey is the context key fetchRepoUnread hands
+// conditionalTransport an If-Modified-Since under.
+type ifModifiedSinceKey struct{}
+
+// conditionalTransport sends If-Modified-Since on the first page of a
+// notifications listing whose request context carries one, so polling
+// doesn't reprocess an unchanged inbox. It holds no state of its own, so
+// `serve` handlers can make requests alongside a poll.
+type conditionalTransport struct {
+	base http.RoundTripper
+	// guard is set by newClient for -rate-limit-buffer.
+	guard *rateLimitGuard
+}
+
+func (t *conditionalTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	since, _ := req.Context().Value(ifModifiedSinceKey{}).(string)
+	if page := req.URL.Query().Get("page"); since != "" && (page == "" || page == "1") {
+		req = req.Clone(req.Context())
+		req.Header.Set("If-Modified-Since", since)
+	}
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the optional YAML config file.
+type Config struct {
+	Token          string             `yaml:"token,omitempty"`
+	Repos          []string           `yaml:"repos,omitempty"`
+	DefaultProfile string             `yaml:"default_profile,omitempty"`
+	Profiles       map[string]Profile `yaml:"profiles,omitempty"`
+	// DefaultRules can be set to false to drop the built-in renovate rules.
+	DefaultRules *bool         `yaml:"default_rules,omitempty"`
+	Rules        []*Rule       `yaml:"rules,omitempty"`
+	Display      DisplayConfig `yaml:"display,omitempty"`
+	// Filters are named sets of filter flags, used with -filter.
+	Filters map[string]NamedFilter `yaml:"filters,omitempty"`
+	// SafeRepos, if set, are the only repos anything may be marked read,
+	// archived or unsubscribed in.
+	SafeRepos []string `yaml:"safe_repos,omitempty"`
+}
+
+// NamedFilter is a saved combination of filter flags. Comma-separated lists
+// work as they do on the command line.
+type NamedFilter struct {
+	Reason        string `yaml:"reason,omitempty"`
+	Type          string `yaml:"type,omitempty"`
+	Participating bool   `yaml:"participating,omitempty"`
+	MaxAge        string `yaml:"max_age,omitempty"`
+}
+
+// DisplayConfig customizes the interactive layout, e.g. for piping it into
+// other renderers.
+type DisplayConfig struct {
+	// Separator is printed before each notification.
+	Separator string `yaml:"separator,omitempty"`
+	// Header is a text/template for the first line of each notification.
+	Header string `yaml:"header,omitempty"`
+	// Prompt is a text/template for -output prompt.
+	Prompt string `yaml:"prompt,omitempty"`
+}
+
+// Profile overrides parts of the config, selected with -profile.
+type Profile struct {
+	Token string   `yaml:"token,omitempty"`
+	Repos []string `yaml:"repos,omitempty"`
+}
+
+// defaultRepos is what we fetch when nothing else is configured.
+var defaultRepos = []string{"runatlantis/atlantis"}
+
+// allRepos is every repo a run with c could fetch: the top-level repos, or
+// the default ones, and each profile's.
+func (c *Config) allRepos() []string {
+	repos := c.Repos
+	if len(repos) == 0 {
+		repos = defaultRepos
+	}
+	repos = slices.Clone(repos)
+	for _, name := range slices.Sorted(maps.Keys(c.Profiles)) {
+		repos = append(repos, c.Profiles[name].Repos...)
+	}
+	return repos
+}
+
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "github-notification-manager", "config.yaml")
+}
+
+// loadConfig parses the config file at path. A missing file is not an error
+// and yields an empty config.
+func loadConfig(path string) (*Config, error) {
+	cfg := &Config{}
+	if path == "" {
+		return cfg, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// validate checks everything that can be checked without talking to GitHub
+// and returns every problem found rather than just the first.
+func (c *Config) validate() []error {
+	var errs []error
+	if c.Token != "" && !validToken(c.Token) {
+		errs = append(errs, fmt.Errorf("token: doesn't look like a GitHub token"))
+	}
+	for _, repo := range c.Repos {
+		if !validRepo(repo) {
+			errs = append(errs, fmt.Errorf("repos: %q is not of the form owner/name", repo))
+		}
+	}
+	for _, repo := range c.SafeRepos {
+		if !validRepo(repo) {
+			errs = append(errs, fmt.Errorf("safe_repos: %q is not of the form owner/name", repo))
+		}
+	}
+	if c.DefaultProfile != "" {
+		if _, ok := c.Profiles[c.DefaultProfile]; !ok {
+			errs = append(errs, fmt.Errorf("default_profile: no profile named %q", c.DefaultProfile))
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(c.Profiles)) {
+		p := c.Profiles[name]
+		if p.Token != "" && !validToken(p.Token) {
+			errs = append(errs, fmt.Errorf("profiles.%s.token: doesn't look like a GitHub token", name))
+		}
+		for _, repo := range p.Repos {
+			if !validRepo(repo) {
+				errs = append(errs, fmt.Errorf("profiles.%s.repos: %q is not of the form owner/name", name, repo))
+			}
+		}
+	}
+	if c.Display.Header != "" {
+		if _, err := parseHeader(c.Display.Header); err != nil {
+			errs = append(errs, fmt.Errorf("display.header: %w", err))
+		}
+	}
+	if c.Display.Prompt != "" {
+		if _, err := parsePrompt(c.Display.Prompt); err != nil {
+			errs = append(errs, fmt.Errorf("display.prompt: %w", err))
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(c.Filters)) {
+		if f := c.Filters[name]; f.MaxAge != "" {
+			if _, err := parseAge(f.MaxAge); err != nil {
+				errs = append(errs, fmt.Errorf("filters.%s.max_age: %w", name, err))
+			}
+		}
+	}
+	for i, r := range c.Rules {
+		if err := r.compile(); err != nil {
+			errs = append(errs, fmt.Errorf("rules[%d]: %w", i, err))
+		}
+		for _, name := range r.Profiles {
+			if _, ok := c.Profiles[name]; !ok {
+				errs = append(errs, fmt.Errorf("rules[%d]: no profile named %q", i, name))
+			}
+		}
+	}
+	return errs
+}
+
+// rules returns the rules active for the given profile, built-ins first.
+// The config must already have been validated.
+func (c *Config) rules(profile string) []*Rule {
+	var rules []*Rule
+	if c.DefaultRules == nil || *c.DefaultRules {
+		rules = append(rules, defaultRules()...)
+	}
+	for _, r := range c.Rules {
+		if r.appliesTo(profile) {
+			rules = append(rules, r)
+		}
+	}
+	return rules
+}
+
+var (
+	repoPattern  = regexp.MustCompile(`^[A-Za-z0-9_.-]+/[A-Za-z0-9_.-]+$`)
+	tokenPattern = regexp.MustCompile(`^(gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,}|[0-9a-f]{40})$`)
+)
+
+func validRepo(repo string) bool {
+	return repoPattern.MatchString(repo)
+}
+
+func validToken(token string) bool {
+	return tokenPattern.MatchString(token)
+}
+
+// runConfig implements the `config` subcommand.
+func runConfig(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: github-notification-manager config validate|show [flags]")
+		return exitError
+	}
+	switch args[0] {
+	case "validate":
+		return runConfigValidate(args[1:])
+	case "show":
+		return runConfigShow(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "unknown config subcommand %q\n", args[0])
+		return exitError
+	}
+}
+
+func runConfigValidate(args []string) int {
+	fs := flag.NewFlagSet("config validate", flag.ExitOnError)
+	path := fs.String("config", defaultConfigPath(), "path to the config file")
+	fs.Parse(args)
+
+	cfg, err := loadConfig(*path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitConfig
+	}
+	errs := cfg.validate()
+	if len(errs) > 0 {
+		for _, err := range errs {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		}
+		fmt.Fprintf(os.Stderr, "%d problem(s) found in %s\n", len(errs), *path)
+		return exitConfig
+	}
+	fmt.Printf("✅ %s is valid.\n", *path)
+	return exitOK
+}
+
+// activeProfile resolves -profile against the config's default_profile.
+func (c *Config) activeProfile(name string) (string, *Profile, error) {
+	if name == "" {
+		name = c.DefaultProfile
+	}
+	if name == "" {
+		return "", nil, nil
+	}
+	p, ok := c.Profiles[name]
+	if !ok {
+		names := slices.Sorted(maps.Keys(c.Profiles))
+		return "", nil, fmt.Errorf("no profile named %q (have: %s)", name, strings.Join(names, ", "))
+	}
+	return name, &p, nil
+}
+
+// runConfigShow prints the effective settings for a run with the given flags,
+// with secrets redacted.
+func runConfigShow(args []string) int {
+	fs := flag.NewFlagSet("config show", flag.ExitOnError)
+	var opts Options
+	opts.register(fs)
+	output := fs.String("output", "yaml", "output format: yaml or json")
+	fs.Parse(args)
+
+	settings, err := opts.resolve()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitStatus(err)
+	}
+	settings = settings.redacted()
+
+	switch *output {
+	case "yaml":
+		enc := yaml.NewEncoder(os.Stdout)
+		enc.SetIndent(2)
+		err = enc.Encode(settings)
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(settings)
+	default:
+		err = fmt.Errorf("unknown output format %q", *output)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitError
+	}
+	return exitOK
+}
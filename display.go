@@ -0,0 +1,195 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/google/go-github/v66/github"
+	"github.com/hako/durafmt"
+)
+
+const (
+	defaultSeparator = "──────────────────────────────"
+	defaultHeader    = "{{.Icon}}  {{.Title}} ({{.ID}})"
+	defaultDetail    = `Repo: {{.Repository.FullName}}
+Type: {{.Subject.Type}}
+Reason: {{.Reason}}
+{{- if .StateChange}}
+ℹ️  state change (merged or closed; usually just informational)
+{{- end}}
+{{- if .CIActivity}}
+🤖 CI activity (a workflow run you triggered)
+{{- end}}
+{{- if not .Linked}}
+URL:  {{.WebURL}}{{if .APIURL}} (api: {{.APIURL}}){{end}}
+{{- else if .APIURL}}
+API:  {{.APIURL}}
+{{- end}}
+Updated: {{.Updated}}
+{{- if .UnreadFor}}
+Sat unread: {{.UnreadFor}}
+{{- end}}
+`
+)
+
+// headerData is what the header and detail templates see: the notification
+// itself plus a few derived fields.
+type headerData struct {
+	*github.Notification
+	Icon        string
+	WebURL      string
+	StateChange bool
+	CIActivity  bool
+	// Title is the subject's title, made a link to WebURL when Linked.
+	Title  string
+	Linked bool
+	// APIURL is only set with -show-api-url.
+	APIURL string
+	// Updated is UpdatedAt in the -time-format.
+	Updated string
+	// Reason is the notification's reason, in its color when the display is
+	// colored.
+	Reason string
+	// UnreadFor is how long a thread that's been read went between its last
+	// update and being read, from last_read_at. It's empty for unread
+	// threads and ones GitHub gives no read time for.
+	UnreadFor string
+}
+
+// templateFuncs are available to the header and detail templates.
+var templateFuncs = template.FuncMap{
+	"uiURL": uiURL,
+	"ago": func(t github.Timestamp) string {
+		return formatTime(t.Time, time.Now(), "short-relative")
+	},
+	"truncate": func(width int, s string) string { return truncate(s, width) },
+}
+
+func parseHeader(text string) (*template.Template, error) {
+	return template.New("header").Option("missingkey=error").Funcs(templateFuncs).Parse(text)
+}
+
+func parseDetail(text string) (*template.Template, error) {
+	return template.New("detail").Option("missingkey=error").Funcs(templateFuncs).Parse(text)
+}
+
+// display renders notifications in the interactive loop.
+type display struct {
+	timeFormat string
+	settings   *Settings
+	header     *template.Template
+	detail     *template.Template
+	// showAPIURL prints the raw subject URL next to the web one, for
+	// debugging uiURL.
+	showAPIURL bool
+	// hyperlinks makes titles links to the web URL instead of printing it.
+	hyperlinks bool
+	// color shows reasons in reasonColor.
+	color bool
+	// bodies caches what printBody fetched, by subject URL.
+	bodies map[string]string
+}
+
+func newDisplay(settings *Settings, timeFormat string) (*display, error) {
+	header, err := parseHeader(settings.Header)
+	if err != nil {
+		return nil, fmt.Errorf("header template: %w", err)
+	}
+	detail, err := parseDetail(settings.Detail)
+	if err != nil {
+		return nil, fmt.Errorf("detail template: %w", err)
+	}
+	return &display{timeFormat: timeFormat, settings: settings, header: header, detail: detail}, nil
+}
+
+func (d *display) printSeparator() {
+	fmt.Println(d.settings.Separator)
+}
+
+// blockPool recycles the buffers notification blocks are rendered into.
+// bytes.Buffer keeps its capacity across Reset, so after the first few
+// blocks rendering doesn't allocate for the text.
+var blockPool = sync.Pool{New: func() any { return new(bytes.Buffer) }}
+
+// printNotification renders the whole block into a pooled buffer and
+// writes it in one go.
+func (d *display) printNotification(n *github.Notification) {
+	d.writeNotification(os.Stdout, n)
+}
+
+func (d *display) writeNotification(w io.Writer, n *github.Notification) {
+	b := blockPool.Get().(*bytes.Buffer)
+	defer func() {
+		b.Reset()
+		blockPool.Put(b)
+	}()
+	d.renderNotification(b, n)
+	w.Write(b.Bytes())
+}
+
+func (d *display) renderNotification(b *bytes.Buffer, n *github.Notification) {
+	subject := n.GetSubject()
+	now := time.Now()
+	icon := subjectIcon(subject.GetType())
+	if d.settings.stale(n, now) {
+		icon = "🕸️ "
+	}
+	data := headerData{
+		Notification: n,
+		Icon:         icon,
+		WebURL:       uiURL(subject.GetURL()),
+		StateChange:  n.GetReason() == "state_change",
+		CIActivity:   n.GetReason() == "ci_activity",
+		Updated:      formatTime(n.GetUpdatedAt().Time, now, d.timeFormat),
+		Reason:       n.GetReason(),
+	}
+	if read := n.GetLastReadAt().Time; !n.GetUnread() && read.After(n.GetUpdatedAt().Time) {
+		data.UnreadFor = durafmt.Parse(read.Sub(n.GetUpdatedAt().Time)).LimitFirstN(2).String()
+	}
+	if d.color {
+		data.Reason = colorize(data.Reason, reasonColor[data.Reason])
+	}
+	data.Title = subject.GetTitle()
+	if d.hyperlinks {
+		data.Title, data.Linked = hyperlink(data.WebURL, data.Title), true
+	}
+	if d.showAPIURL {
+		data.APIURL = subject.GetURL()
+	}
+	if err := d.header.Execute(b, data); err != nil {
+		log.Printf("⚠️  Failed to render header: %v\n", err)
+	}
+	b.WriteString("\n")
+	if err := d.detail.Execute(b, data); err != nil {
+		log.Printf("⚠️  Failed to render details: %v\n", err)
+	}
+}
+
+// subjectIcon picks an icon by subject type so the list can be scanned by
+// shape as well as text.
+func subjectIcon(subjectType string) string {
+	switch subjectType {
+	case "PullRequest":
+		return "🔀"
+	case "Issue":
+		return "🐛"
+	case "Release":
+		return "🏷️ "
+	case "Discussion":
+		return "💬"
+	case "Commit":
+		return "📝"
+	case "CheckSuite", "CheckRun", "WorkflowRun":
+		return "🤖"
+	case "RepositoryVulnerabilityAlert", "RepositoryDependabotAlertsThread":
+		return "🚨"
+	default:
+		return "🔔"
+	}
+}
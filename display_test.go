@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/lukemassa/github-notification-manager/testutil"
+)
+
+func TestSubjectIcon(t *testing.T) {
+	tests := []struct {
+		subjectType, want string
+	}{
+		{"PullRequest", "🔀"},
+		{"Issue", "🐛"},
+		{"Release", "🏷️ "},
+		{"Discussion", "💬"},
+		{"Commit", "📝"},
+		{"CheckSuite", "🤖"},
+		{"CheckRun", "🤖"},
+		{"WorkflowRun", "🤖"},
+		{"RepositoryVulnerabilityAlert", "🚨"},
+		{"RepositoryDependabotAlertsThread", "🚨"},
+		{"TeamDiscussion", "🔔"},
+		{"pullrequest", "🔔"},
+		{"", "🔔"},
+	}
+	for _, tt := range tests {
+		if got := subjectIcon(tt.subjectType); got != tt.want {
+			t.Errorf("subjectIcon(%q) = %q, want %q", tt.subjectType, got, tt.want)
+		}
+	}
+}
+
+// BenchmarkWriteNotification compares rendering into blockPool with
+// rendering into a fresh buffer for every block, as the loop did before.
+func BenchmarkWriteNotification(b *testing.B) {
+	d, err := newDisplay(&Settings{Header: defaultHeader, Detail: defaultDetail}, "relative")
+	if err != nil {
+		b.Fatal(err)
+	}
+	n := testutil.NewNotification("1", "lukemassa/example", "chore(deps): update module golang.org/x/term to v0.37.0", time.Now().Add(-time.Hour))
+
+	b.Run("pooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			d.writeNotification(io.Discard, n)
+		}
+	})
+	b.Run("fresh", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			var buf bytes.Buffer
+			d.renderNotification(&buf, n)
+			io.Discard.Write(buf.Bytes())
+		}
+	})
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"log"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/google/go-github/v66/github"
+)
+
+// checkSuiteBranch pulls the branch out of a check suite notification's
+// title, e.g. "CI workflow run failed for my-feature branch". The title is
+// all the notification says about which PR it's for.
+var checkSuiteBranch = regexp.MustCompile(` for (\S+) branch$`)
+
+// ownDraftCI looks up, for each check suite notification, whether its
+// branch has an open draft PR by the authenticated user, returning what it
+// found as details by thread ID for the own_draft_ci rule field. That's one
+// request for the user plus one per repo and branch. Notifications whose
+// branch can't be worked out, or whose lookup fails, are left out, so no
+// rule on own_draft_ci matches them and they're asked about.
+func (e *enricher) ownDraftCI(ctx context.Context, notifications []*github.Notification) map[string]*subjectDetails {
+	type branch struct{ repo, name string }
+	pending := map[branch][]string{}
+	for _, n := range notifications {
+		if n.GetSubject().GetType() != "CheckSuite" {
+			continue
+		}
+		if m := checkSuiteBranch.FindStringSubmatch(n.GetSubject().GetTitle()); m != nil {
+			b := branch{n.GetRepository().GetFullName(), m[1]}
+			pending[b] = append(pending[b], n.GetID())
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	me, _, err := e.client.Users.Get(ctx, "")
+	if err != nil {
+		log.Printf("⚠️  Failed to look up who you are for own_draft_ci: %v\n", err)
+		return nil
+	}
+
+	details := map[string]*subjectDetails{}
+	for b, threads := range pending {
+		owner, repo, _ := strings.Cut(b.repo, "/")
+		// Head only matches branches in the repo itself, not forks, which
+		// is where your own PRs' CI runs anyway.
+		prs, _, err := e.client.PullRequests.List(ctx, owner, repo, &github.PullRequestListOptions{State: "open", Head: owner + ":" + b.name})
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("⚠️  Failed to look up pull requests for %s %s: %v\n", b.repo, b.name, err)
+			}
+			continue
+		}
+		own := slices.ContainsFunc(prs, func(pr *github.PullRequest) bool {
+			return pr.GetDraft() && strings.EqualFold(pr.GetUser().GetLogin(), me.GetLogin())
+		})
+		for _, id := range threads {
+			details[id] = &subjectDetails{OwnDraftCI: own}
+		}
+	}
+	return details
+}
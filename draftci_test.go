@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v66/github"
+	"github.com/lukemassa/github-notification-manager/testutil"
+)
+
+func checkSuite(id, repo, title string) *github.Notification {
+	n := testutil.NewNotification(id, repo, title, time.Now())
+	n.Subject.Type = github.String("CheckSuite")
+	n.Subject.URL = nil
+	return n
+}
+
+func pullRequest(author, branch string, draft bool) *github.PullRequest {
+	return &github.PullRequest{
+		User:  &github.User{Login: github.String(author)},
+		Head:  &github.PullRequestBranch{Ref: github.String(branch)},
+		Draft: github.Bool(draft),
+	}
+}
+
+func TestAutoDraftCI(t *testing.T) {
+	server := testutil.NewMockGitHubServer(t)
+	server.Login = "lukemassa"
+	const repo = "lukemassa/example"
+	server.AddPullRequest(repo, pullRequest("LukeMassa", "my-draft", true))
+	server.AddPullRequest(repo, pullRequest("someone-else", "their-draft", true))
+	server.AddPullRequest(repo, pullRequest("lukemassa", "ready", false))
+
+	tests := []struct {
+		n    *github.Notification
+		want string
+	}{
+		{checkSuite("1", repo, "CI workflow run failed for my-draft branch"), "mark-read"},
+		{checkSuite("2", repo, "CI workflow run succeeded for their-draft branch"), ""},
+		{checkSuite("3", repo, "CI workflow run failed for ready branch"), ""},
+		{checkSuite("4", repo, "CI workflow run failed"), ""},
+		{checkSuite("5", repo, "CI workflow run failed for no-pr branch"), ""},
+		// The title alone doesn't make it a check suite.
+		{testutil.NewNotification("6", repo, "Fix CI for my-draft branch", time.Now()), ""},
+	}
+	var notifications []*github.Notification
+	for _, tt := range tests {
+		notifications = append(notifications, tt.n)
+	}
+	settings := &Settings{Rules: builtin("draft-ci")}
+	applyEnrichedFilters(context.Background(), &enricher{client: server.Client()}, notifications, settings)
+	for _, tt := range tests {
+		if got := settings.decide(tt.n, time.Now()).Action; got != tt.want {
+			t.Errorf("%q: decide = %q, want %q", tt.n.GetSubject().GetTitle(), got, tt.want)
+		}
+	}
+}
+
+func TestOwnDraftCIField(t *testing.T) {
+	server := testutil.NewMockGitHubServer(t)
+	server.Login = "lukemassa"
+	const repo = "lukemassa/example"
+	server.AddPullRequest(repo, pullRequest("lukemassa", "my-draft", true))
+	server.AddPullRequest(repo, pullRequest("someone-else", "their-draft", true))
+	notifications := []*github.Notification{
+		checkSuite("1", repo, "CI workflow run failed for my-draft branch"),
+		checkSuite("2", repo, "CI workflow run failed for their-draft branch"),
+		checkSuite("3", repo, "CI workflow run completed"),
+	}
+	details := (&enricher{client: server.Client()}).ownDraftCI(context.Background(), notifications)
+
+	r := builtin("draft-ci")[0]
+	for i, want := range []string{"true", "false", ""} {
+		n := notifications[i]
+		if got := r.value(n, details[n.GetID()]); got != want {
+			t.Errorf("%q: own_draft_ci = %q, want %q", n.GetSubject().GetTitle(), got, want)
+		}
+	}
+}
+
+func TestConfigRuleOverridesDraftCI(t *testing.T) {
+	server := testutil.NewMockGitHubServer(t)
+	server.Login = "lukemassa"
+	server.AddPullRequest("lukemassa/example", pullRequest("lukemassa", "my-draft", true))
+	n := checkSuite("1", "lukemassa/example", "CI workflow run failed for my-draft branch")
+
+	keep := &Rule{Name: "watch-example", Field: "repo", Equals: "lukemassa/example", Action: "skip"}
+	if err := keep.compile(); err != nil {
+		t.Fatal(err)
+	}
+	settings := &Settings{Rules: addRules([]*Rule{keep}, builtin("draft-ci"))}
+	applyEnrichedFilters(context.Background(), &enricher{client: server.Client()}, []*github.Notification{n}, settings)
+	if d := settings.decide(n, time.Now()); d.Action != "skip" || d.Source != "watch-example" {
+		t.Errorf("decide = %+v, want the config rule's skip", d)
+	}
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"context"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v66/github"
+)
+
+// subjectDetails is what we learn about a notification's subject (the PR or
+// issue) by fetching it. None of it is in the notification payload.
+type subjectDetails struct {
+	State   string   `json:"state"`
+	Merged  bool     `json:"merged"`
+	Draft   bool     `json:"draft"`
+	Author  string   `json:"author"`
+	Labels  []string `json:"labels"`
+	HTMLURL string   `json:"html_url"`
+	// Assignees and Reviewers are logins; Reviewers is only set for PRs.
+	Assignees   []string `json:"assignees,omitempty"`
+	Reviewers   []string `json:"reviewers,omitempty"`
+	CommentsURL string   `json:"comments_url,omitempty"`
+	// Commenters is only filled in by enrichParticipants.
+	Commenters []string `json:"commenters,omitempty"`
+	// HeadSHA is only set for PRs. CIState is only filled in by enrichCI.
+	HeadSHA string `json:"head_sha,omitempty"`
+	CIState string `json:"ci_state,omitempty"`
+	// OwnDraftCI is only set for check suites, by ownDraftCI.
+	OwnDraftCI bool `json:"own_draft_ci,omitempty"`
+}
+
+// participants is everyone we know to be involved in the thread.
+func (d *subjectDetails) participants() []string {
+	people := []string{d.Author}
+	people = append(people, d.Assignees...)
+	people = append(people, d.Reviewers...)
+	return append(people, d.Commenters...)
+}
+
+// closed reports whether the subject is closed or merged.
+func (d *subjectDetails) closed() bool {
+	return d.State == "closed" || d.Merged
+}
+
+// enrichConcurrency caps how many subject lookups run at once.
+const enrichConcurrency = 8
+
+// enricher fetches subject details, caching them by subject URL so each
+// subject is fetched once until its notification is updated. The cache
+// persists across runs; see enrichcache.go.
+type enricher struct {
+	client *github.Client
+
+	mu    sync.Mutex
+	cache map[string]*cacheEntry
+	dirty bool
+}
+
+func newEnricher(client *github.Client) *enricher {
+	return &enricher{client: client, cache: loadEnrichCache()}
+}
+
+// enrichable reports whether n's subject is something we know how to fetch.
+func enrichable(n *github.Notification) bool {
+	switch n.GetSubject().GetType() {
+	case "PullRequest", "Issue":
+		return n.GetSubject().GetURL() != ""
+	}
+	return false
+}
+
+// enrich fetches details for every enrichable notification concurrently.
+// Failures are logged and leave that notification without details.
+func (e *enricher) enrich(ctx context.Context, notifications []*github.Notification) {
+	forEachEnrichable(ctx, notifications, func(n *github.Notification) error {
+		_, err := e.details(ctx, n)
+		return err
+	})
+}
+
+// enrichParticipants additionally fetches who has commented on each thread.
+// That's an extra request per thread on top of enrich.
+func (e *enricher) enrichParticipants(ctx context.Context, notifications []*github.Notification) {
+	forEachEnrichable(ctx, notifications, func(n *github.Notification) error {
+		d, err := e.details(ctx, n)
+		if err != nil {
+			return err
+		}
+		return e.commenters(ctx, d)
+	})
+}
+
+// forEachEnrichable runs fn on each enrichable notification, at most
+// enrichConcurrency at a time. Errors are logged.
+func forEachEnrichable(ctx context.Context, notifications []*github.Notification, fn func(*github.Notification) error) {
+	forEachWhere(ctx, notifications, enrichable, fn)
+}
+
+// forEachWhere is forEachEnrichable for the notifications want picks.
+func forEachWhere(ctx context.Context, notifications []*github.Notification, want func(*github.Notification) bool, fn func(*github.Notification) error) {
+	sem := make(chan struct{}, enrichConcurrency)
+	var wg sync.WaitGroup
+	for _, n := range notifications {
+		if !want(n) {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			if err := fn(n); err != nil && ctx.Err() == nil {
+				log.Printf("⚠️  Failed to look up %s: %v\n", n.GetSubject().GetURL(), err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// commenters fills in d.Commenters from the first page of comments.
+func (e *enricher) commenters(ctx context.Context, d *subjectDetails) error {
+	e.mu.Lock()
+	done := d.Commenters != nil || d.CommentsURL == ""
+	e.mu.Unlock()
+	if done {
+		return nil
+	}
+	req, err := e.client.NewRequest("GET", d.CommentsURL+"?per_page=100", nil)
+	if err != nil {
+		return err
+	}
+	var comments []struct {
+		User struct {
+			Login string `json:"login"`
+		} `json:"user"`
+	}
+	if _, err := e.client.Do(ctx, req, &comments); err != nil {
+		return err
+	}
+	logins := []string{}
+	for _, c := range comments {
+		if !slices.Contains(logins, c.User.Login) {
+			logins = append(logins, c.User.Login)
+		}
+	}
+	e.mu.Lock()
+	d.Commenters = logins
+	e.dirty = true
+	e.mu.Unlock()
+	return nil
+}
+
+// details returns n's subject details, fetching them if they aren't cached.
+func (e *enricher) details(ctx context.Context, n *github.Notification) (*subjectDetails, error) {
+	url := n.GetSubject().GetURL()
+	if d := e.cached(n); d != nil {
+		return d, nil
+	}
+
+	req, err := e.client.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	// Pull requests and issues share enough shape to decode into one struct.
+	var raw struct {
+		State       string `json:"state"`
+		Merged      bool   `json:"merged"`
+		Draft       bool   `json:"draft"`
+		HTMLURL     string `json:"html_url"`
+		CommentsURL string `json:"comments_url"`
+		User        struct {
+			Login string `json:"login"`
+		} `json:"user"`
+		Labels []struct {
+			Name string `json:"name"`
+		} `json:"labels"`
+		Assignees []struct {
+			Login string `json:"login"`
+		} `json:"assignees"`
+		RequestedReviewers []struct {
+			Login string `json:"login"`
+		} `json:"requested_reviewers"`
+		Head struct {
+			SHA string `json:"sha"`
+		} `json:"head"`
+	}
+	if _, err := e.client.Do(ctx, req, &raw); err != nil {
+		return nil, err
+	}
+	d := &subjectDetails{
+		State:   raw.State,
+		Merged:  raw.Merged,
+		Draft:   raw.Draft,
+		Author:  raw.User.Login,
+		HTMLURL: raw.HTMLURL,
+		HeadSHA: raw.Head.SHA,
+	}
+	for _, l := range raw.Labels {
+		d.Labels = append(d.Labels, l.Name)
+	}
+	for _, a := range raw.Assignees {
+		d.Assignees = append(d.Assignees, a.Login)
+	}
+	for _, r := range raw.RequestedReviewers {
+		d.Reviewers = append(d.Reviewers, r.Login)
+	}
+	// PRs have a review comments URL too, but issue comments are where most
+	// of the conversation on a PR happens.
+	d.CommentsURL = raw.CommentsURL
+
+	e.mu.Lock()
+	e.cache[url] = &cacheEntry{UpdatedAt: n.GetUpdatedAt().Time, FetchedAt: time.Now(), Details: d}
+	e.dirty = true
+	e.mu.Unlock()
+	return d, nil
+}
+
+// cached returns n's details if they've been fetched since n was last updated.
+func (e *enricher) cached(n *github.Notification) *subjectDetails {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	entry, ok := e.cache[n.GetSubject().GetURL()]
+	if !ok || n.GetUpdatedAt().Time.After(entry.UpdatedAt) {
+		return nil
+	}
+	return entry.Details
+}
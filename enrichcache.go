@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// enrichCacheTTL is how long an entry is kept without being looked at again.
+const enrichCacheTTL = 30 * 24 * time.Hour
+
+// cacheEntry is a subject's details as of its notification's UpdatedAt.
+// It's stale once the notification has been updated since.
+type cacheEntry struct {
+	UpdatedAt time.Time       `json:"updated_at"`
+	FetchedAt time.Time       `json:"fetched_at"`
+	Details   *subjectDetails `json:"details"`
+}
+
+func enrichCachePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "github-notification-manager", "enrich.json"), nil
+}
+
+// loadEnrichCache reads the cache from disk. Problems are logged and give an
+// empty cache; it's only a cache.
+func loadEnrichCache() map[string]*cacheEntry {
+	cache := map[string]*cacheEntry{}
+	path, err := enrichCachePath()
+	if err != nil {
+		return cache
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cache
+	}
+	if err == nil {
+		err = json.Unmarshal(data, &cache)
+	}
+	if err != nil {
+		log.Printf("⚠️  Ignoring enrichment cache: %v\n", err)
+		return map[string]*cacheEntry{}
+	}
+	return cache
+}
+
+// save writes the cache back if anything was added, dropping entries that
+// haven't been refreshed within enrichCacheTTL.
+func (e *enricher) save() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !e.dirty {
+		return nil
+	}
+	now := time.Now()
+	for url, entry := range e.cache {
+		if now.Sub(entry.FetchedAt) > enrichCacheTTL {
+			delete(e.cache, url)
+		}
+	}
+
+	if err := saveEnrichCache(e.cache); err != nil {
+		return err
+	}
+	e.dirty = false
+	return nil
+}
+
+func saveEnrichCache(cache map[string]*cacheEntry) error {
+	path, err := enrichCachePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+
+	"github.com/google/go-github/v66/github"
+)
+
+// Exit codes an -exec hook uses to say what to do with the notification.
+// Anything else skips it.
+const (
+	hookMarkRead    = 0
+	hookUnsubscribe = 10
+	hookSnooze      = 20
+)
+
+// runHook runs the -exec command for n and returns its exit code. The
+// command gets the notification as JSON on stdin and in GNM_* variables.
+func runHook(ctx context.Context, command string, n *github.Notification) (int, error) {
+	summary := summarize(n)
+	input, err := json.Marshal(summary)
+	if err != nil {
+		return 0, err
+	}
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(),
+		"GNM_ID="+n.GetID(),
+		"GNM_TITLE="+summary.Title,
+		"GNM_REPO="+summary.Repo,
+		"GNM_TYPE="+summary.Type,
+		"GNM_REASON="+summary.Reason,
+		"GNM_URL="+summary.URL,
+	)
+	err = cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode(), nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return 0, nil
+}
+
+// dispatchHook runs the hook and acts on its exit code.
+func (t *triager) dispatchHook(ctx context.Context, command string, n *github.Notification) {
+	code, err := runHook(ctx, command, n)
+	if err != nil {
+		log.Printf("⚠️  Failed to run -exec hook: %v\n", err)
+		t.tally.failed++
+		return
+	}
+	switch code {
+	case hookMarkRead:
+		t.markRead(ctx, n)
+	case hookUnsubscribe:
+		t.unsubscribe(ctx, n)
+	case hookSnooze:
+		t.snooze(n)
+	default:
+		t.skip(n, fmt.Sprintf("hook exited %d", code))
+	}
+}
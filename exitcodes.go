@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/google/go-github/v66/github"
+)
+
+// Exit statuses, so scripts can tell failures apart. They're listed in the
+// README; keep it in step.
+const (
+	exitOK          = 0
+	exitError       = 1
+	exitAuth        = 2
+	exitRateLimit   = 3
+	exitNetwork     = 4
+	exitInterrupted = 5
+	exitConfig      = 6
+)
+
+// configError marks a problem with the config file.
+type configError struct{ error }
+
+func (e configError) Unwrap() error { return e.error }
+
+// exitStatus picks the exit status for err.
+func exitStatus(err error) int {
+	var rateErr *github.RateLimitError
+	var abuseErr *github.AbuseRateLimitError
+	var respErr *github.ErrorResponse
+	var cfgErr configError
+	var lowErr *rateLimitLowError
+	switch {
+	case errors.Is(err, context.Canceled):
+		return exitInterrupted
+	case errors.As(err, &rateErr), errors.As(err, &abuseErr), errors.As(err, &lowErr):
+		return exitRateLimit
+	case errors.As(err, &respErr) && respErr.Response != nil && respErr.Response.StatusCode == http.StatusUnauthorized:
+		return exitAuth
+	case errors.As(err, &cfgErr):
+		return exitConfig
+	// A timeout is almost always GitHub or the network being slow.
+	case errors.Is(err, context.DeadlineExceeded), transientNetworkError(err):
+		return exitNetwork
+	default:
+		return exitError
+	}
+}
+
+// fail reports err, with what we were doing, and returns the exit status for
+// it. Timeouts and interruptions get a plain explanation instead of an error.
+func fail(err error, what string) int {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		fmt.Fprintln(os.Stderr, "⏱️  Gave up: -timeout ran out. GitHub may be slow; try a longer one.")
+	case errors.Is(err, context.Canceled):
+		fmt.Fprintln(os.Stderr, "🛑 Interrupted.")
+	case what == "":
+		log.Print(err)
+	default:
+		log.Printf("%s: %v", what, err)
+	}
+	return exitStatus(err)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// isolate points the config, state and token lookups at an empty home.
+func isolate(t *testing.T) string {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(home, ".cache"))
+	t.Setenv("NETRC", filepath.Join(home, ".netrc"))
+	t.Setenv("GITHUB_TOKEN", "")
+	return home
+}
+
+func TestSubcommandExitCodes(t *testing.T) {
+	home := isolate(t)
+	badConfig := filepath.Join(home, "bad.yaml")
+	if err := os.WriteFile(badConfig, []byte("repos: [not a repo\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	state := &State{History: []HistoryEntry{{ID: "1", Action: "read", At: time.Now()}}}
+	if err := state.save(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		run  func() int
+		want int
+	}{
+		{"serve without a token", func() int { return runServe(nil) }, exitAuth},
+		{"reopen without a token", func() int { return runReopen([]string{"1"}) }, exitAuth},
+		{"subscriptions without a token", func() int { return runSubscriptions([]string{"list"}) }, exitAuth},
+		{"serve with a bad config", func() int { return runServe([]string{"-config", badConfig}) }, exitConfig},
+		{"reopen with a bad config", func() int { return runReopen([]string{"-config", badConfig, "1"}) }, exitConfig},
+		{"subscriptions with a bad config", func() int { return runSubscriptions([]string{"list", "-config", badConfig}) }, exitConfig},
+		{"config show with a bad config", func() int { return runConfig([]string{"show", "-config", badConfig}) }, exitConfig},
+		{"config validate with a bad config", func() int { return runConfig([]string{"validate", "-config", badConfig}) }, exitConfig},
+		{"reopen something never handled", func() int { return runReopen([]string{"2"}) }, exitError},
+		{"unknown config subcommand", func() int { return runConfig([]string{"frobnicate"}) }, exitError},
+	}
+	for _, tt := range tests {
+		if got := tt.run(); got != tt.want {
+			t.Errorf("%s: exit %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v66/github"
+	"github.com/lukemassa/github-notification-manager/testutil"
+)
+
+// addNotifications adds count notifications to repo, with IDs prefix1,
+// prefix2, and so on.
+func addNotifications(server *testutil.MockGitHubServer, repo, prefix string, count int) {
+	for i := 1; i <= count; i++ {
+		id := fmt.Sprintf("%s%d", prefix, i)
+		server.AddNotification(testutil.NewNotification(id, repo, "Notification "+id, time.Now()))
+	}
+}
+
+func notificationIDs(notifications []*github.Notification) []string {
+	var ids []string
+	for _, n := range notifications {
+		ids = append(ids, n.GetID())
+	}
+	return ids
+}
+
+func TestFetchAllUnreadPagination(t *testing.T) {
+	server := testutil.NewMockGitHubServer(t)
+	server.PageSize = 2
+	addNotifications(server, "lukemassa/example", "a", 3)
+
+	notifications, stats, err := fetchAllUnread(context.Background(), server.Client(), []string{"lukemassa/example"}, fetchOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a1", "a2", "a3"}; !slices.Equal(notificationIDs(notifications), want) {
+		t.Errorf("fetched %v, want %v", notificationIDs(notifications), want)
+	}
+	if stats.pages != 2 {
+		t.Errorf("fetched %d pages, want 2", stats.pages)
+	}
+}
+
+func TestFetchAllUnreadPageError(t *testing.T) {
+	server := testutil.NewMockGitHubServer(t)
+	server.PageSize = 2
+	addNotifications(server, "lukemassa/example", "a", 3)
+	server.FailPage("lukemassa/example", 2, http.StatusInternalServerError)
+
+	notifications, _, err := fetchAllUnread(context.Background(), server.Client(), []string{"lukemassa/example"}, fetchOptions{})
+	var respErr *github.ErrorResponse
+	if !errors.As(err, &respErr) || respErr.Response.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("err = %v, want the page 2 error", err)
+	}
+	if notifications != nil {
+		t.Errorf("fetched %v alongside the error, want nothing", notificationIDs(notifications))
+	}
+}
+
+func BenchmarkFetchAllUnread(b *testing.B) {
+	server := testutil.NewMockGitHubServer(b)
+	base := time.Now()
+	for i := range 1000 {
+		// Out of order, so the sort has work to do.
+		updated := base.Add(-time.Duration(i*7919%1000) * time.Minute)
+		id := fmt.Sprint(i)
+		server.AddNotification(testutil.NewNotification(id, "lukemassa/example", "Notification "+id, updated))
+	}
+	client := server.Client()
+	repos := []string{"lukemassa/example"}
+	b.ReportAllocs()
+	for b.Loop() {
+		notifications, stats, err := fetchAllUnread(context.Background(), client, repos, fetchOptions{})
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(notifications) != 1000 || stats.pages != 10 {
+			b.Fatalf("fetched %d notifications in %d pages, want 1000 in 10", len(notifications), stats.pages)
+		}
+		sortNewestFirst(notifications)
+	}
+}
+
+func TestFetchAllUnreadDedupesRepoCase(t *testing.T) {
+	server := testutil.NewMockGitHubServer(t)
+	addNotifications(server, "lukemassa/example", "a", 2)
+	addNotifications(server, "lukemassa/other", "b", 1)
+
+	repos := []string{"Lukemassa/Example", "lukemassa/other", "lukemassa/example"}
+	notifications, _, err := fetchAllUnread(context.Background(), server.Client(), repos, fetchOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a1", "a2", "b1"}; !slices.Equal(notificationIDs(notifications), want) {
+		t.Errorf("fetched %v, want %v", notificationIDs(notifications), want)
+	}
+}
+
+func TestFetchAllUnreadSkipsMissingRepo(t *testing.T) {
+	server := testutil.NewMockGitHubServer(t)
+	addNotifications(server, "lukemassa/example", "a", 1)
+	addNotifications(server, "lukemassa/other", "b", 1)
+	server.FailRepo("lukemassa/renamed", http.StatusNotFound)
+
+	repos := []string{"lukemassa/example", "lukemassa/renamed", "lukemassa/other"}
+	notifications, stats, err := fetchAllUnread(context.Background(), server.Client(), repos, fetchOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a1", "b1"}; !slices.Equal(notificationIDs(notifications), want) {
+		t.Errorf("fetched %v, want %v", notificationIDs(notifications), want)
+	}
+	if want := []string{"lukemassa/renamed"}; !slices.Equal(stats.missing, want) {
+		t.Errorf("missing = %v, want %v", stats.missing, want)
+	}
+}
+
+func TestArchivedRepos(t *testing.T) {
+	now := time.Now()
+	archived := func(id, repo string) *github.Notification {
+		n := testutil.NewNotification(id, repo, id, now)
+		n.Repository.Archived = github.Bool(true)
+		return n
+	}
+	notifications := []*github.Notification{
+		archived("1", "lukemassa/old"),
+		testutil.NewNotification("2", "lukemassa/example", "2", now),
+		archived("3", "lukemassa/attic"),
+		archived("4", "lukemassa/old"),
+	}
+	if got, want := archivedRepos(notifications), []string{"lukemassa/attic", "lukemassa/old"}; !slices.Equal(got, want) {
+		t.Errorf("archivedRepos = %v, want %v", got, want)
+	}
+}
+
+func TestFetchAllUnreadKeepsUnchangedRepos(t *testing.T) {
+	server := testutil.NewMockGitHubServer(t)
+	addNotifications(server, "lukemassa/example", "a", 1)
+	addNotifications(server, "lukemassa/other", "b", 1)
+	repos := []string{"lukemassa/example", "lukemassa/other"}
+	client := server.ClientWithTransport(func(base http.RoundTripper) http.RoundTripper {
+		return &conditionalTransport{base: base}
+	})
+
+	first, stats, err := fetchAllUnread(context.Background(), client, repos, fetchOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a1", "b1"}; !slices.Equal(notificationIDs(first), want) {
+		t.Fatalf("first fetch got %v, want %v", notificationIDs(first), want)
+	}
+
+	// Only lukemassa/other changes, so lukemassa/example answers 304.
+	server.AddNotification(testutil.NewNotification("b2", "lukemassa/other", "b2", time.Now()))
+	second, stats, err := fetchAllUnread(context.Background(), client, repos, fetchOptions{Previous: stats.listings})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a1", "b1", "b2"}; !slices.Equal(notificationIDs(second), want) {
+		t.Errorf("second fetch got %v, want %v", notificationIDs(second), want)
+	}
+	if stats.unchanged != 1 {
+		t.Errorf("unchanged = %d, want 1", stats.unchanged)
+	}
+
+	// Nothing changes: everything comes from the listings.
+	third, stats, err := fetchAllUnread(context.Background(), client, repos, fetchOptions{Previous: stats.listings})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a1", "b1", "b2"}; !slices.Equal(notificationIDs(third), want) || stats.unchanged != 2 {
+		t.Errorf("third fetch got %v with %d unchanged, want %v with 2", notificationIDs(third), stats.unchanged, want)
+	}
+}
+
+func TestListingKeyUnreusable(t *testing.T) {
+	if key := (fetchOptions{}).listingKey("Lukemassa/Example"); key != (fetchOptions{}).listingKey("lukemassa/example") || key == "" {
+		t.Errorf("listingKey differs by case or is empty: %q", key)
+	}
+	if key := (fetchOptions{Participating: true}).listingKey("lukemassa/example"); key == (fetchOptions{}).listingKey("lukemassa/example") {
+		t.Errorf("-participating shares a listing key with the full listing: %q", key)
+	}
+	for _, fo := range []fetchOptions{{All: true}, {Since: time.Now()}} {
+		if key := fo.listingKey("lukemassa/example"); key != "" {
+			t.Errorf("%+v: listingKey = %q, want none", fo, key)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v66/github"
+)
+
+// filterNotifications returns the notifications for which keep is true,
+// preserving order.
+func filterNotifications(notifications []*github.Notification, keep func(*github.Notification) bool) []*github.Notification {
+	var kept []*github.Notification
+	for _, n := range notifications {
+		if keep(n) {
+			kept = append(kept, n)
+		}
+	}
+	return kept
+}
+
+// dedupeByID drops repeats of a notification, keeping the first. The same
+// repo listed twice (GitHub names are case-insensitive, so "Org/Repo" and
+// "org/repo" both work) would otherwise list its notifications twice.
+func dedupeByID(notifications []*github.Notification) []*github.Notification {
+	seen := map[string]struct{}{}
+	return filterNotifications(notifications, func(n *github.Notification) bool {
+		if _, dup := seen[n.GetID()]; dup {
+			return false
+		}
+		seen[n.GetID()] = struct{}{}
+		return true
+	})
+}
+
+// applyFilters applies every client-side filter the settings ask for.
+func applyFilters(notifications []*github.Notification, settings *Settings) []*github.Notification {
+	if settings.OnlyRead || settings.OnlyUnread {
+		notifications = filterNotifications(notifications, func(n *github.Notification) bool {
+			return n.GetUnread() == settings.OnlyUnread
+		})
+	}
+	if len(settings.Reasons) > 0 {
+		notifications = filterByReason(notifications, settings.Reasons)
+	}
+	if len(settings.Types) > 0 {
+		notifications = filterNotifications(notifications, func(n *github.Notification) bool {
+			return slices.Contains(settings.Types, n.GetSubject().GetType())
+		})
+	}
+	if settings.MaxAge > 0 {
+		notifications = filterByMaxAge(notifications, time.Duration(settings.MaxAge), time.Now())
+	}
+	if settings.MinAge > 0 {
+		notifications = filterByMinAge(notifications, time.Duration(settings.MinAge), time.Now())
+	}
+	return notifications
+}
+
+// filterByMaxAge drops notifications last updated more than maxAge before now.
+func filterByMaxAge(notifications []*github.Notification, maxAge time.Duration, now time.Time) []*github.Notification {
+	return filterNotifications(notifications, func(n *github.Notification) bool {
+		return now.Sub(n.GetUpdatedAt().Time) <= maxAge
+	})
+}
+
+// filterByMinAge drops notifications updated less than minAge before now.
+func filterByMinAge(notifications []*github.Notification, minAge time.Duration, now time.Time) []*github.Notification {
+	return filterNotifications(notifications, func(n *github.Notification) bool {
+		return now.Sub(n.GetUpdatedAt().Time) >= minAge
+	})
+}
+
+func filterByReason(notifications []*github.Notification, reasons []string) []*github.Notification {
+	return filterNotifications(notifications, func(n *github.Notification) bool {
+		return slices.Contains(reasons, n.GetReason())
+	})
+}
+
+// needsEnrichment reports whether any filter needs subject details.
+func (s *Settings) needsEnrichment() bool {
+	return s.OnlyOpen || s.Involves != "" || s.CIFailed || len(s.Labels) > 0 || len(s.ExcludeLabels) > 0 ||
+		s.decidesOnDetails()
+}
+
+// decidesOnDetails reports whether decide needs subject details.
+func (s *Settings) decidesOnDetails() bool {
+	return s.IgnoreDrafts || s.AutoReadDrafts || s.IgnoreClosed || slices.ContainsFunc(s.Rules, (*Rule).needsSubject)
+}
+
+// decidesOnDraftCI reports whether a rule needs ownDraftCI's lookup.
+func (s *Settings) decidesOnDraftCI() bool {
+	return slices.ContainsFunc(s.Rules, func(r *Rule) bool { return r.Field == "own_draft_ci" })
+}
+
+// applyEnrichedFilters applies the filters that need subject details,
+// looking them up first, and reports what they dropped. With -resolve-urls it
+// also looks up the web URLs uiURL can't guess, and for a rule on
+// own_draft_ci which CI notifications are for our own draft PRs.
+func applyEnrichedFilters(ctx context.Context, e *enricher, notifications []*github.Notification, settings *Settings) []*github.Notification {
+	if settings.ResolveURLs {
+		e.resolveURLs(ctx, notifications)
+	}
+	settings.details = nil
+	if settings.decidesOnDraftCI() {
+		settings.details = e.ownDraftCI(ctx, notifications)
+	}
+	if !settings.needsEnrichment() {
+		return notifications
+	}
+	if settings.UseGraphQL {
+		e.prefetchGraphQL(ctx, notifications)
+	}
+	if settings.Involves != "" {
+		e.enrichParticipants(ctx, notifications)
+	} else {
+		e.enrich(ctx, notifications)
+	}
+	if settings.CIFailed {
+		e.enrichCI(ctx, notifications)
+	}
+	if settings.OnlyOpen {
+		before := len(notifications)
+		notifications = filterNotifications(notifications, func(n *github.Notification) bool {
+			if n.GetSubject().GetType() != "PullRequest" {
+				return true
+			}
+			d := e.cached(n)
+			return d == nil || !d.closed()
+		})
+		if dropped := before - len(notifications); dropped > 0 {
+			statusf("🚪 Filtered %d merged or closed pull request(s).\n", dropped)
+		}
+	}
+	if settings.Involves != "" {
+		notifications = filterNotifications(notifications, func(n *github.Notification) bool {
+			d := e.cached(n)
+			return d != nil && slices.ContainsFunc(d.participants(), func(login string) bool {
+				return strings.EqualFold(login, settings.Involves)
+			})
+		})
+	}
+	if len(settings.Labels) > 0 || len(settings.ExcludeLabels) > 0 {
+		before := len(notifications)
+		notifications = filterNotifications(notifications, func(n *github.Notification) bool {
+			return labelsMatch(e.cached(n), settings.Labels, settings.ExcludeLabels)
+		})
+		if dropped := before - len(notifications); dropped > 0 {
+			statusf("🏷️  Filtered %d notification(s) by label.\n", dropped)
+		}
+	}
+	if settings.decidesOnDetails() {
+		if settings.details == nil {
+			settings.details = map[string]*subjectDetails{}
+		}
+		for _, n := range notifications {
+			if d := e.cached(n); d != nil {
+				settings.details[n.GetID()] = d
+			}
+		}
+	}
+
+	if settings.CIFailed {
+		notifications = filterNotifications(notifications, e.ciFailed)
+		reportByRepo("🔥", "with failing CI", notifications)
+	}
+	return notifications
+}
+
+// reportByRepo notes how many notifications are left and how they split
+// across repos, e.g. "🔥 3 with failing CI: a/b (2), c/d (1)".
+func reportByRepo(icon, what string, notifications []*github.Notification) {
+	counts := countBy(summarizeAll(notifications), func(s NotificationSummary) string { return s.Repo })
+	var parts []string
+	for _, c := range counts {
+		parts = append(parts, fmt.Sprintf("%s (%d)", c.key, c.count))
+	}
+	line := fmt.Sprintf("%s %d %s", icon, len(notifications), what)
+	if len(parts) > 0 {
+		line += ": " + strings.Join(parts, ", ")
+	}
+	statusf("%s.\n", line)
+}
+
+// labelsMatch reports whether a subject with details d has one of include
+// (if any are given) and none of exclude. Labels compare case-insensitively,
+// as on GitHub. Without details there are no labels to go on.
+func labelsMatch(d *subjectDetails, include, exclude []string) bool {
+	var labels []string
+	if d != nil {
+		labels = d.Labels
+	}
+	has := func(want []string) bool {
+		return slices.ContainsFunc(labels, func(l string) bool {
+			return slices.ContainsFunc(want, func(w string) bool { return strings.EqualFold(l, w) })
+		})
+	}
+	return (len(include) == 0 || has(include)) && !has(exclude)
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v66/github"
+	"github.com/lukemassa/github-notification-manager/testutil"
+)
+
+func TestDedupeByID(t *testing.T) {
+	now := time.Now()
+	first := testutil.NewNotification("1", "Lukemassa/Example", "first", now)
+	notifications := []*github.Notification{
+		first,
+		testutil.NewNotification("2", "Lukemassa/Example", "second", now),
+		testutil.NewNotification("1", "lukemassa/example", "first", now),
+		testutil.NewNotification("3", "lukemassa/example", "third", now),
+		testutil.NewNotification("2", "lukemassa/example", "second", now),
+	}
+	got := dedupeByID(notifications)
+	if want := []string{"1", "2", "3"}; !slices.Equal(notificationIDs(got), want) {
+		t.Fatalf("dedupeByID = %v, want %v", notificationIDs(got), want)
+	}
+	if got[0] != first {
+		t.Errorf("dedupeByID kept a later copy of 1, want the first")
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"time"
+)
+
+// gcWarnSize is how big the state file, enrichment cache and kept listings
+// can get together before a run suggests `gc`.
+const gcWarnSize = 5 << 20
+
+// runGC implements `gc`, which drops local data older than -older-than: history
+// entries, cached subject lookups and snoozes that have run out. It also
+// drops the -conditional listings for repos the config no longer has.
+func runGC(args []string) int {
+	fs := flag.NewFlagSet("gc", flag.ExitOnError)
+	olderThan := Age(90 * 24 * time.Hour)
+	fs.Var(&olderThan, "older-than", "drop history and cached lookups older than this (e.g. 90d)")
+	dryRun := fs.Bool("dry-run", false, "say what would be dropped without dropping it")
+	configPath := fs.String("config", defaultConfigPath(), "path to the config file, for which repos' listings to keep")
+	fs.Parse(args)
+	if olderThan <= 0 {
+		fmt.Fprintln(os.Stderr, "❌ -older-than must be positive")
+		return exitError
+	}
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ loading config: %v\n", err)
+		return exitConfig
+	}
+
+	state, err := loadState()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ loading state: %v\n", err)
+		return exitError
+	}
+	cache := loadEnrichCache()
+	now := time.Now()
+	cutoff := now.Add(-time.Duration(olderThan))
+
+	history := slices.DeleteFunc(slices.Clone(state.History), func(e HistoryEntry) bool { return e.At.Before(cutoff) })
+	droppedHistory := len(state.History) - len(history)
+	droppedCache := 0
+	for url, entry := range cache {
+		if entry.FetchedAt.Before(cutoff) {
+			delete(cache, url)
+			droppedCache++
+		}
+	}
+	listings := loadListings()
+	droppedListings := pruneListings(listings, cfg.allRepos())
+	expired := 0
+	for id, until := range state.Snoozed {
+		if !now.Before(until) {
+			delete(state.Snoozed, id)
+			expired++
+		}
+	}
+
+	summary := fmt.Sprintf("%d history entries, %d cached lookups, %d listings for unconfigured repos and %d expired snoozes", droppedHistory, droppedCache, droppedListings, expired)
+	if *dryRun {
+		fmt.Printf("🧪 (dry run) Would drop %s.\n", summary)
+		return exitOK
+	}
+	state.History = history
+	if err := state.save(); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ saving state: %v\n", err)
+		return exitError
+	}
+	if droppedCache > 0 {
+		if err := saveEnrichCache(cache); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ saving enrichment cache: %v\n", err)
+			return exitError
+		}
+	}
+	if droppedListings > 0 {
+		if err := writeListings(listings); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ saving listings: %v\n", err)
+			return exitError
+		}
+	}
+	fmt.Printf("🧹 Dropped %s.\n", summary)
+	return exitOK
+}
+
+// warnIfStoreLarge suggests `gc` when the local data has grown past
+// gcWarnSize. It only looks; nothing is dropped without asking.
+func warnIfStoreLarge() {
+	var total int64
+	for _, path := range []func() (string, error){statePath, enrichCachePath, listingCachePath} {
+		p, err := path()
+		if err != nil {
+			continue
+		}
+		if info, err := os.Stat(p); err == nil {
+			total += info.Size()
+		}
+	}
+	if total > gcWarnSize {
+		log.Printf("⚠️  Local data is %.1f MB; `github-notification-manager gc -older-than 90d` trims it.\n", float64(total)/(1<<20))
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestGCPrunesListings(t *testing.T) {
+	home := isolate(t)
+	config := filepath.Join(home, "config.yaml")
+	yaml := "repos: [lukemassa/example]\nprofiles:\n  work:\n    repos: [LukeMassa/Work]\n"
+	if err := os.WriteFile(config, []byte(yaml), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	listings := map[string]*listing{}
+	for _, repo := range []string{"lukemassa/example", "lukemassa/work", "lukemassa/gone"} {
+		for _, participating := range []bool{false, true} {
+			listings[(fetchOptions{Participating: participating}).listingKey(repo)] = &listing{LastModified: "Mon, 01 Jan 2024 00:00:00 GMT"}
+		}
+	}
+	if err := writeListings(listings); err != nil {
+		t.Fatal(err)
+	}
+
+	if code := runGC([]string{"-config", config, "-dry-run"}); code != exitOK {
+		t.Fatalf("gc -dry-run exited %d", code)
+	}
+	if got := len(loadListings()); got != 6 {
+		t.Fatalf("-dry-run left %d listings, want all 6", got)
+	}
+	if code := runGC([]string{"-config", config}); code != exitOK {
+		t.Fatalf("gc exited %d", code)
+	}
+	want := []string{
+		"lukemassa/example?participating=false",
+		"lukemassa/example?participating=true",
+		"lukemassa/work?participating=false",
+		"lukemassa/work?participating=true",
+	}
+	if got := slices.Sorted(maps.Keys(loadListings())); !slices.Equal(got, want) {
+		t.Errorf("kept %v, want %v", got, want)
+	}
+}
+
+func TestAllRepos(t *testing.T) {
+	if got := (&Config{}).allRepos(); !slices.Equal(got, defaultRepos) {
+		t.Errorf("empty config: %v, want the default repos %v", got, defaultRepos)
+	}
+	cfg := &Config{Profiles: map[string]Profile{"work": {Repos: []string{"lukemassa/work"}}}}
+	if got, want := cfg.allRepos(), append(slices.Clone(defaultRepos), "lukemassa/work"); !slices.Equal(got, want) {
+		t.Errorf("profile only: %v, want %v", got, want)
+	}
+}
@@ -4,10 +4,13 @@ go 1.25.0
 
 require (
 	github.com/google/go-github/v66 v66.0.0
+	github.com/hako/durafmt v0.0.0-20210608085754-5c1018a4e16b
 	golang.org/x/oauth2 v0.32.0
+	golang.org/x/term v0.37.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/google/go-querystring v1.1.0 // indirect
-	github.com/hako/durafmt v0.0.0-20210608085754-5c1018a4e16b // indirect
+	golang.org/x/sys v0.38.0 // indirect
 )
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v66/github"
+)
+
+// graphQLBatch is how many subjects go in one GraphQL query. GitHub limits
+// queries by node count, and each subject asks for a few connections.
+const graphQLBatch = 50
+
+// subjectRef identifies a PR or issue from its API URL.
+type subjectRef struct {
+	owner, repo string
+	number      int
+	pull        bool
+}
+
+// parseSubjectURL parses https://api.github.com/repos/o/r/pulls/1 (or
+// issues/1).
+func parseSubjectURL(apiURL string) (subjectRef, bool) {
+	path, ok := strings.CutPrefix(apiURL, "https://api.github.com/repos/")
+	if !ok {
+		return subjectRef{}, false
+	}
+	parts := strings.Split(path, "/")
+	if len(parts) != 4 || (parts[2] != "pulls" && parts[2] != "issues") {
+		return subjectRef{}, false
+	}
+	number, err := strconv.Atoi(parts[3])
+	if err != nil {
+		return subjectRef{}, false
+	}
+	return subjectRef{owner: parts[0], repo: parts[1], number: number, pull: parts[2] == "pulls"}, true
+}
+
+const graphQLPullFields = `state isDraft url headRefOid author { login } labels(first: 50) { nodes { name } } assignees(first: 20) { nodes { login } } reviewRequests(first: 20) { nodes { requestedReviewer { ... on User { login } } } }`
+
+const graphQLIssueFields = `state url author { login } labels(first: 50) { nodes { name } } assignees(first: 20) { nodes { login } }`
+
+// graphQLSubject is a PR or issue as the query above returns it.
+type graphQLSubject struct {
+	State      string `json:"state"`
+	IsDraft    bool   `json:"isDraft"`
+	URL        string `json:"url"`
+	HeadRefOid string `json:"headRefOid"`
+	Author     struct {
+		Login string `json:"login"`
+	} `json:"author"`
+	Labels struct {
+		Nodes []struct {
+			Name string `json:"name"`
+		} `json:"nodes"`
+	} `json:"labels"`
+	Assignees struct {
+		Nodes []struct {
+			Login string `json:"login"`
+		} `json:"nodes"`
+	} `json:"assignees"`
+	ReviewRequests struct {
+		Nodes []struct {
+			RequestedReviewer struct {
+				Login string `json:"login"`
+			} `json:"requestedReviewer"`
+		} `json:"nodes"`
+	} `json:"reviewRequests"`
+}
+
+// details converts to what the REST lookup would have produced.
+func (g *graphQLSubject) details(ref subjectRef) *subjectDetails {
+	d := &subjectDetails{
+		State:   strings.ToLower(g.State),
+		Draft:   g.IsDraft,
+		Author:  g.Author.Login,
+		HTMLURL: g.URL,
+		HeadSHA: g.HeadRefOid,
+		// The REST comments URL, so enrichParticipants works the same.
+		CommentsURL: fmt.Sprintf("https://api.github.com/repos/%s/%s/issues/%d/comments", ref.owner, ref.repo, ref.number),
+	}
+	if d.State == "merged" {
+		d.State, d.Merged = "closed", true
+	}
+	for _, l := range g.Labels.Nodes {
+		d.Labels = append(d.Labels, l.Name)
+	}
+	for _, a := range g.Assignees.Nodes {
+		d.Assignees = append(d.Assignees, a.Login)
+	}
+	for _, r := range g.ReviewRequests.Nodes {
+		if login := r.RequestedReviewer.Login; login != "" { // teams have no login
+			d.Reviewers = append(d.Reviewers, login)
+		}
+	}
+	return d
+}
+
+// prefetchGraphQL looks up the subjects that aren't cached with one GraphQL
+// query per graphQLBatch of them, instead of a REST request each. Anything
+// it can't fetch is left for the REST lookups to retry.
+func (e *enricher) prefetchGraphQL(ctx context.Context, notifications []*github.Notification) {
+	var pending []*github.Notification
+	for _, n := range notifications {
+		if enrichable(n) && e.cached(n) == nil {
+			pending = append(pending, n)
+		}
+	}
+	for start := 0; start < len(pending); start += graphQLBatch {
+		batch := pending[start:min(start+graphQLBatch, len(pending))]
+		if err := e.graphQLBatch(ctx, batch); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Printf("⚠️  GraphQL lookup failed, falling back to REST: %v\n", err)
+		}
+	}
+}
+
+func (e *enricher) graphQLBatch(ctx context.Context, batch []*github.Notification) error {
+	refs := map[string]subjectRef{}
+	byAlias := map[string]*github.Notification{}
+	var query strings.Builder
+	query.WriteString("query {")
+	for i, n := range batch {
+		ref, ok := parseSubjectURL(n.GetSubject().GetURL())
+		if !ok {
+			continue
+		}
+		alias := "s" + strconv.Itoa(i)
+		refs[alias], byAlias[alias] = ref, n
+		kind, fields := "issue", graphQLIssueFields
+		if ref.pull {
+			kind, fields = "pullRequest", graphQLPullFields
+		}
+		fmt.Fprintf(&query, " %s: repository(owner: %s, name: %s) { subject: %s(number: %d) { %s } }",
+			alias, strconv.Quote(ref.owner), strconv.Quote(ref.repo), kind, ref.number, fields)
+	}
+	query.WriteString(" }")
+	if len(refs) == 0 {
+		return nil
+	}
+
+	req, err := e.client.NewRequest("POST", "graphql", map[string]string{"query": query.String()})
+	if err != nil {
+		return err
+	}
+	// Errors for individual subjects (e.g. no access) come back alongside the
+	// data with that alias null, so they're not treated as fatal.
+	var resp struct {
+		Data map[string]*struct {
+			Subject *graphQLSubject `json:"subject"`
+		} `json:"data"`
+	}
+	if _, err := e.client.Do(ctx, req, &resp); err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for alias, repo := range resp.Data {
+		n, ok := byAlias[alias]
+		if !ok || repo == nil || repo.Subject == nil {
+			continue
+		}
+		e.cache[n.GetSubject().GetURL()] = &cacheEntry{
+			UpdatedAt: n.GetUpdatedAt().Time,
+			FetchedAt: time.Now(),
+			Details:   repo.Subject.details(refs[alias]),
+		}
+		e.dirty = true
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"time"
+
+	"github.com/google/go-github/v66/github"
+)
+
+// maxHistory caps how many actions the state file remembers.
+const maxHistory = 1000
+
+// HistoryEntry records something we did to a notification.
+type HistoryEntry struct {
+	ID     string    `json:"id"`
+	Title  string    `json:"title"`
+	Repo   string    `json:"repo"`
+	URL    string    `json:"url"`
+	Action string    `json:"action"`
+	At     time.Time `json:"at"`
+	// Reason and UpdatedAt are the notification's, for `report`. Entries
+	// from older versions don't have them.
+	Reason    string    `json:"reason,omitempty"`
+	UpdatedAt time.Time `json:"updated_at,omitzero"`
+}
+
+// record appends an action to the history, dropping the oldest entries
+// beyond maxHistory.
+func (s *State) record(n *github.Notification, action string) {
+	s.History = append(s.History, HistoryEntry{
+		ID:        n.GetID(),
+		Title:     n.GetSubject().GetTitle(),
+		Repo:      n.GetRepository().GetFullName(),
+		URL:       uiURL(n.GetSubject().GetURL()),
+		Action:    action,
+		At:        time.Now(),
+		Reason:    n.GetReason(),
+		UpdatedAt: n.GetUpdatedAt().Time,
+	})
+	if extra := len(s.History) - maxHistory; extra > 0 {
+		s.History = s.History[extra:]
+	}
+}
+
+// lastAction returns the most recent history entry for id.
+func (s *State) lastAction(id string) (HistoryEntry, bool) {
+	for i := len(s.History) - 1; i >= 0; i-- {
+		if s.History[i].ID == id {
+			return s.History[i], true
+		}
+	}
+	return HistoryEntry{}, false
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"html/template"
+	"io"
+	"time"
+)
+
+// htmlTemplate is deliberately self-contained with inline styles so the
+// digest renders the same when pasted into an email. Opened in a browser, the
+// script adds a filter box and sorting by clicking a column header; mail
+// clients drop it, which leaves the plain digest.
+var htmlTemplate = template.Must(template.New("digest").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>GitHub notifications — {{.Generated.Format "2006-01-02 15:04 MST"}}</title>
+</head>
+<body style="font-family: -apple-system, Helvetica, Arial, sans-serif; color: #24292f;">
+<h1 style="font-size: 20px;">GitHub notifications ({{len .All}})</h1>
+<input id="filter" type="search" placeholder="Filter…" hidden style="padding: 4px 8px; font-size: 14px; width: 300px;">
+{{range .Groups}}
+<h2 style="font-size: 16px; margin-top: 24px;">{{.Repo}} ({{len .Notifications}})</h2>
+<table class="notifications" style="border-collapse: collapse; width: 100%; font-size: 14px;">
+<tr style="background: #f6f8fa; text-align: left;">
+<th style="padding: 6px; border: 1px solid #d0d7de;">Title</th>
+<th style="padding: 6px; border: 1px solid #d0d7de;">Type</th>
+<th style="padding: 6px; border: 1px solid #d0d7de;">Reason</th>
+<th style="padding: 6px; border: 1px solid #d0d7de;">Updated</th>
+</tr>
+{{range .Notifications}}
+<tr>
+<td style="padding: 6px; border: 1px solid #d0d7de;"><a href="{{.URL}}" style="color: #0969da;">{{.Title}}</a></td>
+<td style="padding: 6px; border: 1px solid #d0d7de;">{{.Type}}</td>
+<td style="padding: 6px; border: 1px solid #d0d7de;">{{.Reason}}</td>
+<td style="padding: 6px; border: 1px solid #d0d7de;" data-sort="{{.UpdatedAt.Format "2006-01-02T15:04:05Z07:00"}}">{{.UpdatedAt.Format "2006-01-02 15:04"}}</td>
+</tr>
+{{end}}
+</table>
+{{end}}
+<p style="color: #57606a; font-size: 12px; margin-top: 24px;">Generated {{.Generated.Format "2006-01-02 15:04 MST"}}</p>
+<script>
+(function () {
+  var filter = document.getElementById("filter");
+  var tables = document.querySelectorAll("table.notifications");
+  filter.hidden = false;
+  filter.addEventListener("input", function () {
+    var q = filter.value.toLowerCase();
+    tables.forEach(function (table) {
+      var shown = 0;
+      table.querySelectorAll("tr:not(:first-child)").forEach(function (row) {
+        var match = row.textContent.toLowerCase().indexOf(q) !== -1;
+        row.hidden = !match;
+        if (match) shown++;
+      });
+      table.hidden = shown === 0;
+      table.previousElementSibling.hidden = shown === 0;
+    });
+  });
+  tables.forEach(function (table) {
+    table.querySelectorAll("th").forEach(function (th, col) {
+      var ascending = false;
+      th.style.cursor = "pointer";
+      th.addEventListener("click", function () {
+        ascending = !ascending;
+        var rows = Array.prototype.slice.call(table.querySelectorAll("tr:not(:first-child)"));
+        rows.sort(function (a, b) {
+          var x = a.cells[col].dataset.sort || a.cells[col].textContent;
+          var y = b.cells[col].dataset.sort || b.cells[col].textContent;
+          return ascending ? x.localeCompare(y) : y.localeCompare(x);
+        });
+        rows.forEach(function (row) { table.appendChild(row); });
+      });
+    });
+  });
+})();
+</script>
+</body>
+</html>
+`))
+
+type repoGroup struct {
+	Repo          string
+	Notifications []NotificationSummary
+}
+
+// groupByRepo groups summaries by repo, keeping repos in order of first
+// appearance and notifications in their original order.
+func groupByRepo(summaries []NotificationSummary) []repoGroup {
+	var groups []repoGroup
+	index := map[string]int{}
+	for _, s := range summaries {
+		i, ok := index[s.Repo]
+		if !ok {
+			i = len(groups)
+			index[s.Repo] = i
+			groups = append(groups, repoGroup{Repo: s.Repo})
+		}
+		groups[i].Notifications = append(groups[i].Notifications, s)
+	}
+	return groups
+}
+
+func writeHTML(w io.Writer, summaries []NotificationSummary) error {
+	return htmlTemplate.Execute(w, struct {
+		All       []NotificationSummary
+		Groups    []repoGroup
+		Generated time.Time
+	}{summaries, groupByRepo(summaries), time.Now()})
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// icalGrace is how long after its last update a notification's to-do is due.
+const icalGrace = 24 * time.Hour
+
+// writeICal prints a VCALENDAR with a VTODO per notification, for importing
+// into a calendar or reminders app.
+func writeICal(w io.Writer, summaries []NotificationSummary) error {
+	const stamp = "20060102T150405Z"
+	now := time.Now().UTC().Format(stamp)
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//github-notification-manager//EN",
+	}
+	for _, s := range summaries {
+		lines = append(lines,
+			"BEGIN:VTODO",
+			// The URL and update time identify the notification well enough
+			// that re-importing an unchanged one updates it in place.
+			"UID:"+icalEscape(s.URL+"@"+s.UpdatedAt.UTC().Format(stamp)),
+			"DTSTAMP:"+now,
+			"SUMMARY:"+icalEscape(s.Title),
+			"DESCRIPTION:"+icalEscape(fmt.Sprintf("%s (%s, %s)\n%s", s.Title, s.Repo, s.Reason, s.URL)),
+			"URL:"+s.URL,
+			"DUE:"+s.UpdatedAt.Add(icalGrace).UTC().Format(stamp),
+			"STATUS:NEEDS-ACTION",
+			"END:VTODO",
+		)
+	}
+	lines = append(lines, "END:VCALENDAR")
+
+	for _, line := range lines {
+		if _, err := io.WriteString(w, icalFold(line)+"\r\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// icalEscaper escapes TEXT values per RFC 5545 section 3.3.11.
+var icalEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+func icalEscape(s string) string {
+	return icalEscaper.Replace(s)
+}
+
+// icalFold splits a content line into lines of at most 75 octets, each
+// continuation starting with a space (RFC 5545 section 3.1). It never splits
+// a UTF-8 sequence.
+func icalFold(line string) string {
+	const limit = 75
+	var b strings.Builder
+	width := 0
+	for _, r := range line {
+		size := len(string(r))
+		if width+size > limit {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += size
+	}
+	return b.String()
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v66/github"
+)
+
+// parseKeep parses -keep, e.g. "reason=mention,repo=myorg/critical", into
+// rules. A notification is kept if any of them matches.
+func parseKeep(s string) ([]*Rule, error) {
+	var rules []*Rule
+	for _, item := range splitList(s) {
+		field, value, ok := strings.Cut(item, "=")
+		if !ok || value == "" {
+			return nil, fmt.Errorf("-keep: %q is not field=value", item)
+		}
+		r := &Rule{Name: "keep " + item, Field: field, Equals: value, Action: "skip"}
+		if err := r.compile(); err != nil {
+			return nil, fmt.Errorf("-keep: %w", err)
+		}
+		if r.needsDetails() {
+			return nil, fmt.Errorf("-keep: %s needs a lookup, which -keep doesn't do", field)
+		}
+		rules = append(rules, r)
+	}
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("-keep needs at least one field=value")
+	}
+	return rules, nil
+}
+
+// keepTriage marks everything read except what matches keep, after asking
+// unless yes is set, and reports what it kept.
+func keepTriage(ctx context.Context, prompt *prompter, t *triager, notifications []*github.Notification, keep []*Rule, yes bool) {
+	var kept, noise []*github.Notification
+	for _, n := range notifications {
+		if matchRule(keep, n, nil) != nil {
+			kept = append(kept, n)
+		} else {
+			noise = append(noise, n)
+		}
+	}
+	if len(noise) == 0 {
+		fmt.Printf("📌 Everything matches -keep; nothing to mark read.\n")
+		return
+	}
+	if !yes {
+		text, err := prompt.ask(ctx, fmt.Sprintf("Mark %d notification(s) read and keep %d? [y/N]: ", len(noise), len(kept)))
+		if err != nil {
+			return
+		}
+		if text := strings.ToLower(text); text != "y" && text != "yes" {
+			fmt.Println("👍 Left everything alone.")
+			return
+		}
+	}
+
+	t.readAll(ctx, noise)
+	t.say(fmt.Sprintf("✅ Marked %d read.", t.tally.read))
+	if len(kept) > 0 {
+		fmt.Printf("📌 Kept %d:\n", len(kept))
+		for _, n := range kept {
+			fmt.Printf("   %s (%s, %s)\n", n.GetSubject().GetTitle(), n.GetRepository().GetFullName(), n.GetReason())
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/google/go-github/v66/github"
+)
+
+// runLintRules implements `lint-rules`, which runs the configured rules
+// against a sample notification and shows which match and why. It's for
+// debugging rules without waiting for a real notification.
+func runLintRules(args []string) int {
+	fs := flag.NewFlagSet("lint-rules", flag.ExitOnError)
+	var opts Options
+	opts.register(fs)
+	sample := fs.String("sample-notification", "", `notification JSON as the API returns it, e.g. '{"subject":{"title":"chore(deps): bump x","type":"PullRequest"},"reason":"subscribed"}'`)
+	fs.Parse(args)
+	if *sample == "" {
+		fmt.Fprintln(os.Stderr, "usage: github-notification-manager lint-rules -sample-notification '<json>' [flags]")
+		return exitError
+	}
+
+	settings, err := opts.resolve()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitStatus(err)
+	}
+	var n github.Notification
+	if err := json.Unmarshal([]byte(*sample), &n); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ -sample-notification: %v\n", err)
+		return exitError
+	}
+
+	if len(settings.Rules) == 0 {
+		fmt.Println("No rules configured.")
+	}
+	first := matchRule(settings.Rules, &n, nil)
+	for _, r := range settings.Rules {
+		mark := "❌"
+		if r.matches(&n, nil) {
+			mark = "✅"
+		}
+		fmt.Printf("%s %s: %s %q %s\n", mark, r.Name, r.Field, r.value(&n, nil), r.describe())
+		if r == first {
+			fmt.Printf("   ↳ first match, so this one applies (%s)\n", r.Action)
+		}
+	}
+
+	d := settings.decide(&n, time.Now())
+	switch d.Action {
+	case "":
+		fmt.Println("➡️  Would ask.")
+	default:
+		fmt.Printf("➡️  Would %s (%s).\n", d.Action, d.Why)
+	}
+	return exitOK
+}
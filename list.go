@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// writeList prints one dense line per notification, truncated to the
+// terminal width when w is a terminal.
+func writeList(w io.Writer, summaries []NotificationSummary) error {
+	width := 0
+	if f, ok := w.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		width, _, _ = term.GetSize(int(f.Fd()))
+	}
+	now := time.Now()
+	for _, s := range summaries {
+		ref := s.Repo
+		if num := subjectNumber(s.URL); num != "" {
+			ref += "#" + num
+		}
+		line := fmt.Sprintf("%s %s %s %s (%s)", subjectIcon(s.Type), s.ID, ref, s.Title, formatTime(s.UpdatedAt, now, "short-relative"))
+		if _, err := fmt.Fprintln(w, truncate(line, width)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// subjectNumber pulls the PR or issue number out of a web URL, if it has one.
+func subjectNumber(url string) string {
+	for _, kind := range []string{"/pull/", "/issues/"} {
+		if _, num, ok := strings.Cut(url, kind); ok {
+			return num
+		}
+	}
+	return ""
+}
+
+// truncate shortens s to width runes, ending in an ellipsis. A width of zero
+// or less means no limit.
+func truncate(s string, width int) string {
+	r := []rune(s)
+	if width <= 0 || len(r) <= width {
+		return s
+	}
+	if width == 1 {
+		return "…"
+	}
+	return string(r[:width-1]) + "…"
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v66/github"
+)
+
+// readActions are the history actions that leave a thread read on
+// GitHub, so it's no longer in an unread listing.
+var readActions = []string{"read", "auto-read", "archived", "unsubscribed"}
+
+func listingCachePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "github-notification-manager", "listings.json"), nil
+}
+
+// loadListings reads the listings -conditional kept from the last finished
+// run, by listingKey. Problems are logged and give none, which only costs a
+// full fetch.
+func loadListings() map[string]*listing {
+	listings := map[string]*listing{}
+	path, err := listingCachePath()
+	if err != nil {
+		return listings
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return listings
+	}
+	if err == nil {
+		err = json.Unmarshal(data, &listings)
+	}
+	if err != nil {
+		log.Printf("⚠️  Ignoring cached listings: %v\n", err)
+		return map[string]*listing{}
+	}
+	return listings
+}
+
+// saveListings replaces the kept listings with these, less the threads
+// handled since the session started. Whether reading a thread moves a
+// listing's Last-Modified is up to GitHub; dropping them means a 304 next
+// time can't bring them back either way.
+func saveListings(listings map[string]*listing, state *State, started time.Time) error {
+	handled := map[string]bool{}
+	for _, e := range state.History {
+		if !e.At.Before(started) && slices.Contains(readActions, e.Action) {
+			handled[e.ID] = true
+		}
+	}
+	kept := map[string]*listing{}
+	for key, l := range listings {
+		kept[key] = &listing{
+			LastModified: l.LastModified,
+			Notifications: filterNotifications(l.Notifications, func(n *github.Notification) bool {
+				return !handled[n.GetID()]
+			}),
+		}
+	}
+	return writeListings(kept)
+}
+
+// pruneListings drops the listings for repos that aren't in repos any more
+// and returns how many it dropped.
+func pruneListings(listings map[string]*listing, repos []string) int {
+	configured := map[string]bool{}
+	for _, repo := range repos {
+		configured[strings.ToLower(repo)] = true
+	}
+	dropped := 0
+	for key := range listings {
+		repo, _, _ := strings.Cut(key, "?")
+		if !configured[repo] {
+			delete(listings, key)
+			dropped++
+		}
+	}
+	return dropped
+}
+
+func writeListings(listings map[string]*listing) error {
+	path, err := listingCachePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(listings)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v66/github"
+	"github.com/lukemassa/github-notification-manager/testutil"
+)
+
+func TestSaveListingsDropsThreadsReadThisRun(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	started := time.Now()
+	var ns []*github.Notification
+	for _, id := range []string{"read", "earlier", "skipped", "snoozed"} {
+		ns = append(ns, testutil.NewNotification(id, "lukemassa/example", id, started))
+	}
+	state := &State{History: []HistoryEntry{
+		{ID: "earlier", Action: "read", At: started.Add(-time.Hour)},
+		{ID: "read", Action: "read", At: started.Add(time.Second)},
+		{ID: "snoozed", Action: "snoozed", At: started.Add(time.Second)},
+	}}
+	key := (fetchOptions{}).listingKey("lukemassa/example")
+	lm := started.UTC().Format(time.RFC1123)
+	if err := saveListings(map[string]*listing{key: {LastModified: lm, Notifications: ns}}, state, started); err != nil {
+		t.Fatal(err)
+	}
+
+	l := loadListings()[key]
+	if l == nil {
+		t.Fatalf("no listing saved for %s", key)
+	}
+	if l.LastModified != lm {
+		t.Errorf("LastModified = %q, want %q", l.LastModified, lm)
+	}
+	// Only what was read this run goes; skipped and snoozed threads have to
+	// come back.
+	if got, want := notificationIDs(l.Notifications), []string{"earlier", "skipped", "snoozed"}; !slices.Equal(got, want) {
+		t.Errorf("saved %v, want %v", got, want)
+	}
+}
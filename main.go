@@ -1,141 +1,666 @@
 package main
 
 import (
-	"bufio"
 	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
 	"slices"
-	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/google/go-github/v66/github"
-	"github.com/hako/durafmt"
-	"golang.org/x/oauth2"
 )
 
 func main() {
-	ctx := context.Background()
+	defer restoreTerminalOnPanic()
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "config":
+			os.Exit(runConfig(os.Args[2:]))
+		case "reopen":
+			os.Exit(runReopen(os.Args[2:]))
+		case "serve":
+			os.Exit(runServe(os.Args[2:]))
+		case "subscriptions":
+			os.Exit(runSubscriptions(os.Args[2:]))
+		case "lint-rules":
+			os.Exit(runLintRules(os.Args[2:]))
+		case "report":
+			os.Exit(runReport(os.Args[2:]))
+		case "gc":
+			os.Exit(runGC(os.Args[2:]))
+		case "backup":
+			os.Exit(runBackup(os.Args[2:]))
+		case "restore":
+			os.Exit(runRestore(os.Args[2:]))
+		}
+	}
+	os.Exit(run())
+}
+
+// run is the triage run. It returns the exit status rather than exiting so
+// deferred state saving happens.
+func run() int {
+	var opts Options
+	opts.register(flag.CommandLine)
+	output := flag.String("output", "interactive", "interactive, or one of: "+strings.Join(outputFormats, ", "))
+	out := flag.String("out", "", "write non-interactive output to this file instead of stdout")
+	pager := flag.String("pager", "", "pipe non-interactive output through this command (e.g. less); \"auto\" uses $PAGER, falling back to less")
+	todo := flag.String("append-todo", "", "append notifications to this markdown file as `- [ ]` lines (skipping ones already there) instead of triaging")
+	keep := flag.String("keep", "", "mark everything read except notifications matching these comma-separated field=value pairs (fields: title, repo, type, reason)")
+	markAllFlag := flag.Bool("mark-all", false, "mark every notification the filters select read in one go (asks first unless -yes)")
+	batchSize := flag.Int("batch-size", 1, "with -mark-all, -keep or -select, mark a repo's notifications read in one request once there are at least this many (1 means one request each)")
+	selectMode := flag.Bool("select", false, "list notifications numbered and mark a selection (e.g. 1-5,8,12) read in one go instead of prompting for each")
+	markRead := flag.String("mark-read", "", "mark these comma-separated thread IDs (as the json, csv and list outputs show them) read, then exit")
+	annotate := flag.String("annotate", "", "save the note given after the flags against this thread ID (no note removes it), then exit")
+	showAnnotated := flag.Bool("show-annotation", false, "only notifications with an -annotate note")
+	yes := flag.Bool("yes", false, "don't ask before -keep or -mark-all marks things read")
+	watch := flag.Bool("watch", false, "keep polling and print new notifications as they arrive")
+	interval := flag.Duration("interval", time.Minute, "how often -watch polls")
+	beep := flag.Bool("beep", false, "ring the terminal bell when -watch sees something new")
+	execHook := flag.String("exec", "", "run this shell command for each notification instead of prompting; its exit code picks the action (0 read, 10 unsubscribe, 20 snooze, else skip)")
+	snoozeFor := flag.Duration("snooze-for", 24*time.Hour, "how long a snooze lasts")
+	wake := flag.String("wake", "", "un-snooze these comma-separated thread IDs (or \"all\") now and triage just those")
+	throttle := flag.Duration("throttle", 0, "wait at least this long between marking requests (e.g. 200ms), to stay clear of GitHub's secondary rate limits on big inboxes")
+	dryRun := flag.Bool("dry-run", false, "don't change anything on GitHub or in the local state, just say what would happen")
+	confirmAuto := flag.Bool("confirm-each-auto", false, "ask before each auto-approval (Enter confirms)")
+	hyperlinks := flag.Bool("hyperlinks", false, "make titles clickable links (OSC 8) instead of printing the URL, if the terminal looks like it supports them")
+	noColor := flag.Bool("no-color", false, "don't color reasons (also off when NO_COLOR is set or stdout isn't a terminal)")
+	showAPIURL := flag.Bool("show-api-url", false, "show the raw API subject URL next to the web URL (for reporting link bugs)")
+	timeout := flag.Duration("timeout", 0, "give up on the whole run after this long (e.g. 30s); 0 means no limit")
+	showVersion := flag.Bool("version", false, "print version and build info, then exit")
+	sortExprFlag := flag.String("sort-expr", "", "order notifications by this expression instead of newest first, e.g. 'reason == \"review_requested\" ? 0 : 1' or 'a.repo < b.repo' (see the README)")
+	silentEmpty := flag.Bool("silent-empty", false, "print nothing when there are no notifications (for status bars); progress notes go to stderr")
+	timeFormat := flag.String("time-format", "relative", "how to show update times: "+strings.Join(timeFormats, ", ")+", or a Go time layout like \"Jan 2 15:04\"")
+	flag.Parse()
 
-	token := os.Getenv("GITHUB_TOKEN")
-	if token == "" {
-		log.Fatal("GITHUB_TOKEN environment variable is required")
+	if *showVersion {
+		printVersion()
+		return exitOK
+	}
+	if *output != "interactive" && !slices.Contains(outputFormats, *output) {
+		log.Printf("unknown -output %q", *output)
+		return exitError
+	}
+	if *batchSize < 1 {
+		log.Printf("-batch-size must be at least 1")
+		return exitError
+	}
+	*timeFormat = checkTimeFormat(*timeFormat)
+	if *output != "interactive" || *silentEmpty {
+		// Keep stdout for the output itself.
+		statusOut = os.Stderr
+	}
+	var sortBy *sortExpr
+	if *sortExprFlag != "" {
+		e, err := parseSortExpr(*sortExprFlag)
+		if err != nil {
+			return fail(configError{fmt.Errorf("-sort-expr: %w", err)}, "")
+		}
+		sortBy = e
+	}
+	var keepRules []*Rule
+	if *keep != "" {
+		rules, err := parseKeep(*keep)
+		if err != nil {
+			return fail(err, "")
+		}
+		keepRules = rules
+	}
+	if *output == "interactive" && *out != "" {
+		log.Printf("⚠️  -out is ignored in interactive mode\n")
+	}
+	if *pager != "" && (*output == "interactive" || *out != "") {
+		log.Printf("⚠️  -pager only applies to non-interactive output on stdout\n")
+		*pager = ""
 	}
 
-	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
-	tc := oauth2.NewClient(ctx, ts)
-	client := github.NewClient(tc)
+	// Ctrl-C cancels the context so the session can unwind and save state.
+	// Once it has, stop catching SIGINT so a second one exits immediately.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
 
-	notifications, err := fetchAllUnread(ctx, client)
+	settings, err := opts.resolve()
 	if err != nil {
-		log.Fatalf("error fetching notifications: %v", err)
+		return fail(err, "")
 	}
-	if len(notifications) == 0 {
-		fmt.Println("No unread notifications.")
-		return
+	if settings.Token == "" {
+		log.Print("GITHUB_TOKEN environment variable (or a token in the config) is required")
+		return exitAuth
 	}
 
-	sort.Slice(notifications, func(i, j int) bool {
-		return notifications[i].GetUpdatedAt().Time.After(notifications[j].GetUpdatedAt().Time)
-	})
-	slices.Reverse(notifications)
+	client, ct, err := newClient(ctx, settings)
+	if err != nil {
+		return fail(err, "")
+	}
+
+	disp, err := newDisplay(settings, *timeFormat)
+	if err != nil {
+		return fail(configError{err}, "")
+	}
+	disp.showAPIURL = *showAPIURL
+	disp.hyperlinks = *hyperlinks && supportsHyperlinks()
+	disp.color = !*noColor && supportsColor()
+	if *watch {
+		return runWatch(ctx, &watcher{client: client, settings: settings, disp: disp, interval: *interval, beep: *beep})
+	}
+
+	state, err := loadState()
+	if err != nil {
+		return fail(err, "error loading state")
+	}
+	warnIfStoreLarge()
+	fo := settings.fetchOptions()
+	// Waking has to see the inbox even if it hasn't changed.
+	if settings.Conditional && *wake == "" {
+		fo.Previous = loadListings()
+	}
+	defer func() {
+		if *dryRun {
+			return
+		}
+		if err := state.save(); err != nil {
+			log.Printf("⚠️  Failed to save state: %v\n", err)
+		}
+	}()
+
+	if *annotate != "" {
+		return annotateThread(state, *annotate, strings.Join(flag.Args(), " "), *dryRun)
+	}
+	if *markRead != "" {
+		t := &triager{client: client, state: state, dryRun: *dryRun, safeRepos: settings.SafeRepos, throttle: *throttle}
+		return markReadByID(ctx, t, splitList(*markRead))
+	}
+
+	started := time.Now()
+	notifications, fetched, err := fetchAllUnread(ctx, client, settings.Repos, fo)
+	if err != nil {
+		return fail(err, "error fetching notifications")
+	}
+	if settings.Conditional {
+		// Only a session that ran its course keeps what it fetched. If it
+		// was cut short, the next run compares against the listings from
+		// before it, so nothing it didn't get to is lost to a 304.
+		defer func() {
+			if *dryRun || ctx.Err() != nil {
+				return
+			}
+			if err := saveListings(fetched.listings, state, started); err != nil {
+				log.Printf("⚠️  Failed to save listings: %v\n", err)
+			}
+		}()
+	}
+	unchanged := fetched.unchanged > 0 && fetched.unchanged == len(settings.Repos)
+	if unchanged && len(notifications) > 0 {
+		statusf("💤 No changes since the last run; %d notification(s) from it are still unread.\n", len(notifications))
+	}
+	unread := len(notifications)
+	inbox := notifications
+	archived := archivedRepos(notifications)
+	for _, repo := range archived {
+		statusf("🗄️  %s is archived, so it won't get new activity; consider dropping it from repos\n", repo)
+	}
+	if *wake != "" {
+		notifications = wakeSnoozed(state, notifications, splitList(*wake))
+	}
+	notifications = applyFilters(notifications, settings)
+	notifications, snoozed := state.filterSnoozed(notifications, time.Now())
+	if snoozed > 0 {
+		statusf("😴 Hiding %d snoozed notification(s).\n", snoozed)
+	}
+	if *showAnnotated {
+		notifications = filterNotifications(notifications, func(n *github.Notification) bool {
+			return state.Notes[n.GetID()] != ""
+		})
+	}
+	enr := newEnricher(client)
+	notifications = applyEnrichedFilters(ctx, enr, notifications, settings)
+	if err := enr.save(); err != nil {
+		log.Printf("⚠️  Failed to save enrichment cache: %v\n", err)
+	}
+
+	// Everything from here on takes them in the order they're shown: newest
+	// first, or by -sort-expr with ties newest first.
+	sortNewestFirst(notifications)
+	if sortBy != nil {
+		if err := sortBy.sort(notifications); err != nil {
+			log.Printf("⚠️  -sort-expr failed, showing newest first: %v\n", err)
+			sortNewestFirst(notifications)
+		}
+	}
+
+	if *todo != "" {
+		added, existing, err := appendTodo(*todo, notifications)
+		if err != nil {
+			return fail(err, "error appending to "+*todo)
+		}
+		fmt.Printf("📝 Added %d notification(s) to %s (%d already there).\n", added, *todo, existing)
+		return exitOK
+	}
+	if *pager != "" {
+		err := writePaged(pagerCommand(*pager), func(w io.Writer) error {
+			return writeOutput(w, *output, notifications, settings)
+		})
+		if err != nil {
+			return fail(err, "error writing output")
+		}
+		return exitOK
+	}
+	if *output != "interactive" {
+		if err := writeOutputTo(*out, *output, notifications, settings); err != nil {
+			return fail(err, "error writing output")
+		}
+		return exitOK
+	}
 
 	if len(notifications) == 0 {
-		fmt.Println("🎉 No unread notifications!")
-		return
+		streak := state.reachedInboxZero(time.Now())
+		if msg := emptyMessage(*silentEmpty, unchanged, unread); msg != "" {
+			fmt.Println(msg)
+			printStreak(streak)
+		}
+		return exitOK
 	}
+	printSummary(notifications, unread, fetched.pages, settings)
 
-	reader := bufio.NewReader(os.Stdin)
-	for i := len(notifications) - 1; i >= 0; i-- { // newest first
-		n := notifications[i]
+	prompt := newPrompter(os.Stdin)
+	t := &triager{client: client, state: state, snoozeFor: *snoozeFor, dryRun: *dryRun, safeRepos: settings.SafeRepos, throttle: *throttle}
+	t.tally.missingRepos, t.tally.archivedRepos = fetched.missing, archived
+	t.batchSize, t.inbox = *batchSize, inbox
+	if *batchSize > 1 && (settings.Participating || settings.Since > 0) {
+		// The bulk endpoint marks everything in the repo, including what
+		// these left out of the fetch.
+		log.Printf("⚠️  -batch-size is ignored with -participating or -since\n")
+		t.batchSize = 1
+	}
+	if *markAllFlag {
+		markAll(ctx, prompt, t, notifications, *yes)
+		t.retryFailed(ctx, prompt)
+		return exitOK
+	}
+	if keepRules != nil {
+		keepTriage(ctx, prompt, t, notifications, keepRules, *yes)
+		t.retryFailed(ctx, prompt)
+		return exitOK
+	}
+	if *selectMode {
+		selectTriage(ctx, prompt, t, notifications)
+		t.retryFailed(ctx, prompt)
+		return exitOK
+	}
+	if len(notifications) > maxResultsWarning {
+		notifications = offerLimit(ctx, prompt, notifications)
+	}
 
-		subject := n.GetSubject()
-		repo := n.GetRepository()
-		fmt.Println("──────────────────────────────")
-		if isRenovate(n) {
-			fmt.Printf("⚡ Auto Approving: %s\n", subject.GetTitle())
-			markAsRead(ctx, client, n)
-			continue
+	if !*confirmAuto {
+		// Get the auto-approvals out of the way in one go rather than
+		// interleaving them with the manual ones. With -confirm-each-auto the
+		// loop asks about each instead.
+		notifications = t.autoApproveBatch(ctx, notifications, settings)
+	}
+
+	// The queue is newest first. Undo puts things back at the front.
+	queue := slices.Clone(notifications)
+	var actions promptActions
+	actions = promptActions{
+		{key: "y", name: "yes", help: "mark read", run: func(n *github.Notification) { t.markRead(ctx, n) }},
+		{key: "c", name: "copy", help: "copy the link to the clipboard and mark read", run: func(n *github.Notification) {
+			url := uiURL(n.GetSubject().GetURL())
+			if err := copyToClipboard(url); err != nil {
+				fmt.Printf("⚠️  Couldn't copy to the clipboard (%v); here it is: %s\n", err, url)
+			} else {
+				fmt.Printf("📋 Copied %s\n", url)
+			}
+			t.markRead(ctx, n)
+		}},
+		{key: "a", name: "archive", help: "mark done, so it won't come back when the thread updates", run: func(n *github.Notification) { t.archive(ctx, n) }},
+		{key: "u", name: "unsubscribe", help: "unsubscribe from the thread and mark read", run: func(n *github.Notification) { t.unsubscribe(ctx, n) }},
+		{key: "s", name: "snooze", help: fmt.Sprintf("hide it here for %s (-snooze-for)", *snoozeFor), run: t.snooze},
+		{key: "i", name: "info", help: "show the thread's details from GitHub", show: true, run: func(n *github.Notification) { disp.printThreadInfo(ctx, client, n) }},
+		{key: "b", name: "body", help: "show the PR or issue description", show: true, run: func(n *github.Notification) { disp.printBody(ctx, client, n) }},
+		{key: "z", name: "undo", help: "undo the last action and show that notification again", hidden: func() bool { return t.lastUndoable() == nil }, run: func(n *github.Notification) {
+			// Show the undone notification again, then this one.
+			queue = slices.Insert(queue, 0, n)
+			if undone := t.undo(ctx); undone != nil {
+				queue = slices.Insert(queue, 0, undone)
+			}
+		}},
+		{key: "?", name: "help", help: "show this help", show: true, run: func(*github.Notification) { actions.printHelp() }},
+	}
+	// undoTitle says what undo would undo, in the prompt line.
+	undoTitle := func(a promptAction) string {
+		if last := t.lastUndoable(); a.key == "z" && last != nil {
+			return " " + last.GetSubject().GetTitle()
 		}
+		return ""
+	}
+	for len(queue) > 0 && ctx.Err() == nil && ct.guard.tripped() == nil {
+		n := queue[0]
+		queue = queue[1:]
 
-		fmt.Printf("🔔  %s (%s)\n", subject.GetTitle(), n.GetID())
-		fmt.Printf("Repo: %s\n", repo.GetFullName())
-		fmt.Printf("Type: %s\n", subject.GetType())
-		fmt.Printf("URL:  %s\n", uiURL(subject.GetURL()))
-		fmt.Printf("Updated: %s ago\n", durafmt.Parse(time.Since(n.GetUpdatedAt().Time)).LimitFirstN(2))
+		disp.printSeparator()
+		d := settings.decide(n, time.Now())
+		if d.Action == "skip" {
+			t.skip(n, d.Why)
+			continue
+		}
+		if d.Action == "mark-read" {
+			if !*confirmAuto {
+				fmt.Printf("⚡ Auto Approving: %s (%s)\n", n.GetSubject().GetTitle(), d.Why)
+				t.autoRead(ctx, n)
+				continue
+			}
+			text, err := prompt.ask(ctx, fmt.Sprintf("⚡ Auto-approving: %s (%s). OK? [Y/n]: ", n.GetSubject().GetTitle(), d.Why))
+			if err != nil {
+				break
+			}
+			if text := strings.ToLower(text); text != "n" && text != "no" {
+				t.autoRead(ctx, n)
+				continue
+			}
+			// Declined, so it gets the normal prompt.
+		}
 
-		fmt.Print("Mark as read? [y/N]: ")
-		text, _ := reader.ReadString('\n')
-		text = strings.TrimSpace(strings.ToLower(text))
+		if note := state.Notes[n.GetID()]; note != "" {
+			fmt.Printf("📝 %s\n", note)
+		}
+		disp.printNotification(n)
+		if *execHook != "" {
+			t.dispatchHook(ctx, *execHook, n)
+			continue
+		}
 
-		if text == "y" || text == "yes" {
-			markAsRead(ctx, client, n)
+		text, err := prompt.ask(ctx, actions.question(undoTitle))
+		// Actions that only show something don't decide anything, so ask
+		// again after them.
+		for err == nil {
+			a := actions.find(text)
+			if a == nil || !a.show {
+				break
+			}
+			a.run(n)
+			text, err = prompt.ask(ctx, actions.question(undoTitle))
+		}
+		if err != nil {
+			break
+		}
+		if a := actions.find(text); a != nil {
+			a.run(n)
 		} else {
-			fmt.Println("⏭️  Skipped.")
+			t.skip(n, "")
 		}
 	}
 
-	fmt.Println("✅ Done processing notifications.")
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		fmt.Printf("⏱️  Out of time (-timeout %s).\n", *timeout)
+	case ctx.Err() != nil:
+		fmt.Println("🛑 Interrupted.")
+	case ct.guard.tripped() != nil:
+		fmt.Printf("🚦 %v.\n", ct.guard.tripped())
+	default:
+		fmt.Println("✅ Done processing notifications.")
+	}
+	if ct.guard.tripped() == nil {
+		t.retryFailed(ctx, prompt)
+	}
+	t.tally.print()
+	if ctx.Err() != nil {
+		return exitStatus(ctx.Err())
+	}
+	if err := ct.guard.tripped(); err != nil {
+		return exitStatus(err)
+	}
+	if t.tally.clean() {
+		printStreak(state.reachedInboxZero(time.Now()))
+	}
+	return exitOK
 }
 
-func markAsRead(ctx context.Context, client *github.Client, notification *github.Notification) {
-	_, err := client.Activity.MarkThreadRead(ctx, notification.GetID())
-	if err != nil {
-		log.Printf("⚠️  Failed to mark as read: %v\n", err)
-	} else {
-		fmt.Println("✅ Marked as read.")
+// emptyMessage is what to say when there's nothing to triage, given whether
+// -conditional found every repo unchanged and how many unread notifications
+// were fetched before filtering. It's empty with -silent-empty.
+func emptyMessage(silent, unchanged bool, unread int) string {
+	switch {
+	case silent:
+		return ""
+	case unread == 0 && unchanged:
+		return "💤 No changes since the last run."
+	case unread == 0:
+		return "🎉 No unread notifications!"
+	}
+	// They're unread, just not ours to triage this run.
+	return fmt.Sprintf("🎉 Nothing to triage (%d unread hidden by filters or snoozes).", unread)
+}
+
+// sortNewestFirst sorts by last update, newest first, keeping ties in the
+// order GitHub returned them.
+func sortNewestFirst(notifications []*github.Notification) {
+	slices.SortStableFunc(notifications, func(a, b *github.Notification) int {
+		return b.GetUpdatedAt().Time.Compare(a.GetUpdatedAt().Time)
+	})
+}
+
+// statusOut is where progress notes go: stdout normally, stderr when stdout
+// is carrying -output.
+var statusOut io.Writer = os.Stdout
+
+func statusf(format string, args ...any) {
+	fmt.Fprintf(statusOut, format, args...)
+}
+
+// maxResultsWarning is the notification count above which we warn before
+// starting an interactive session.
+const maxResultsWarning = 200
+
+// offerLimit warns about a large inbox and lets the user keep only the newest
+// N notifications. notifications must be sorted newest first.
+func offerLimit(ctx context.Context, prompt *prompter, notifications []*github.Notification) []*github.Notification {
+	fmt.Printf("😬 Heads up: that's more than %d notifications to go through.\n", maxResultsWarning)
+	text, err := prompt.ask(ctx, "Limit to the newest N? [number, Enter for all]: ")
+	if err != nil || text == "" {
+		return notifications
+	}
+	limit, err := strconv.Atoi(text)
+	if err != nil || limit <= 0 {
+		fmt.Println("⚠️  Not a positive number, keeping all.")
+		return notifications
+	}
+	if limit < len(notifications) {
+		notifications = notifications[:limit]
 	}
+	fmt.Printf("👍 Limiting to the newest %d.\n", len(notifications))
+	return notifications
+}
+
+// fetchOptions controls which notifications fetchAllUnread asks GitHub for,
+// and how hard it tries.
+type fetchOptions struct {
+	Participating bool
+	// All fetches read notifications as well as unread ones.
+	All bool
+	// Since, if set, leaves out notifications not updated since then.
+	Since time.Time
+	// Retries is how many times to retry a page on a network error.
+	Retries int
+	// Previous are listings from an earlier fetch, by listingKey. Repos with
+	// one are fetched conditionally, and keep it if nothing changed.
+	Previous map[string]*listing
+}
+
+// listingKey names repo's listing under these options, for reusing it when
+// GitHub says nothing changed. It's empty when the listing can't be reused:
+// a -since cutoff moves every run, and -all listings go stale as soon as
+// anything is read.
+func (fo fetchOptions) listingKey(repo string) string {
+	if fo.All || !fo.Since.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf("%s?participating=%t", strings.ToLower(repo), fo.Participating)
+}
+
+func (s *Settings) fetchOptions() fetchOptions {
+	fo := fetchOptions{Participating: s.Participating, All: s.All}
+	if s.Since > 0 {
+		fo.Since = time.Now().Add(-time.Duration(s.Since))
+	}
+	if s.RetryNetwork {
+		fo.Retries = s.MaxRetries
+	}
+	return fo
+}
+
+// fetchStats is what fetchAllUnread learned along the way besides the
+// notifications themselves.
+type fetchStats struct {
+	pages int
+	// missing are the repos that answered 404, usually because they were
+	// renamed or deleted.
+	missing []string
+	// unchanged counts the repos whose fo.Previous listing still stands.
+	unchanged int
+	// listings are what each repo's notifications came from, by listingKey,
+	// to pass as fo.Previous next time.
+	listings map[string]*listing
 }
 
-func isRenovate(notification *github.Notification) bool {
-	subject := notification.GetSubject()
-	if strings.HasPrefix(subject.GetTitle(), "chore(deps)") {
-		return true
+// fetchAllUnread returns every unread notification (or, with fo.All, every
+// notification) in the given repos. A repo with a listing in fo.Previous is
+// fetched conditionally, and contributes that listing if GitHub says nothing
+// changed. A repo that isn't found is skipped with a warning rather than
+// failing the rest.
+func fetchAllUnread(ctx context.Context, client *github.Client, repos []string, fo fetchOptions) ([]*github.Notification, fetchStats, error) {
+	var all []*github.Notification
+	stats := fetchStats{listings: map[string]*listing{}}
+	for _, repo := range repos {
+		owner, name, _ := strings.Cut(repo, "/")
+		key := fo.listingKey(repo)
+		var prev *listing
+		if key != "" {
+			prev = fo.Previous[key]
+		}
+		l, p, err := fetchRepoUnread(ctx, client, owner, name, fo, prev)
+		stats.pages += p
+		var respErr *github.ErrorResponse
+		switch {
+		case errors.Is(err, errNotModified):
+			l = prev
+			stats.unchanged++
+		case errors.As(err, &respErr) && respErr.Response != nil && respErr.Response.StatusCode == http.StatusNotFound:
+			statusf("⚠️  Skipping %s (not found)\n", repo)
+			stats.missing = append(stats.missing, repo)
+			continue
+		case err != nil:
+			return nil, stats, err
+		}
+		if key != "" {
+			stats.listings[key] = l
+		}
+		all = append(all, l.Notifications...)
 	}
-	if strings.HasPrefix(subject.GetTitle(), "fix(deps)") {
-		return true
+	return dedupeByID(all), stats, nil
+}
+
+// archivedRepos lists, sorted, the repos of notifications that say their
+// repo is archived.
+func archivedRepos(notifications []*github.Notification) []string {
+	var repos []string
+	for _, n := range notifications {
+		if repo := n.GetRepository(); repo.GetArchived() && !slices.Contains(repos, repo.GetFullName()) {
+			repos = append(repos, repo.GetFullName())
+		}
 	}
-	return false
+	slices.Sort(repos)
+	return repos
 }
 
-func fetchAllUnread(ctx context.Context, client *github.Client) ([]*github.Notification, error) {
+// fetchRepoUnread fetches one repo's listing, every page of it. If prev is
+// set the request is conditional on its Last-Modified, and the error is
+// errNotModified if prev still stands.
+func fetchRepoUnread(ctx context.Context, client *github.Client, owner, name string, fo fetchOptions, prev *listing) (*listing, int, error) {
 	opts := &github.NotificationListOptions{
-		All:           false, // unread only
-		Participating: false, // include everything, not just threads you’re directly participating in
+		All:           fo.All,           // unread only, unless -all
+		Participating: fo.Participating, // by default include everything, not just threads you’re directly participating in
+		Since:         fo.Since,
 		ListOptions: github.ListOptions{
 			PerPage: 100, // max page size
 			Page:    1,
 		},
 	}
 
-	var all []*github.Notification
+	reqCtx := ctx
+	if prev != nil && prev.LastModified != "" {
+		reqCtx = context.WithValue(ctx, ifModifiedSinceKey{}, prev.LastModified)
+	}
+	l := &listing{}
+	pages := 0
 	for {
-		ns, resp, err := client.Activity.ListRepositoryNotifications(ctx, "runatlantis", "atlantis", opts)
+		var ns []*github.Notification
+		var resp *github.Response
+		err := withRetry(ctx, fo.Retries, "fetching "+owner+"/"+name, func() error {
+			var err error
+			ns, resp, err = client.Activity.ListRepositoryNotifications(reqCtx, owner, name, opts)
+			return err
+		})
+		if resp != nil && resp.StatusCode == http.StatusNotModified {
+			return nil, pages, errNotModified
+		}
 		if err != nil {
-			return nil, err
+			return nil, pages, err
+		}
+		if pages == 0 {
+			l.LastModified = resp.Header.Get("Last-Modified")
 		}
-		all = append(all, ns...)
+		pages++
+		l.Notifications = append(l.Notifications, ns...)
 
 		if resp.NextPage == 0 {
 			break
 		}
 		opts.ListOptions.Page = resp.NextPage
 	}
-	return all, nil
+	return l, pages, nil
 }
 
+// uiURL is the web page for a subject's API URL: the one -resolve-urls
+// looked up if there is one, otherwise a guess from the URL's shape.
 func uiURL(apiURL string) string {
+	if url, ok := resolvedURL(apiURL); ok {
+		return url
+	}
+	url, _ := guessURL(apiURL)
+	return url
+}
+
+// guessURL maps an API URL to its web page by rewriting it. It reports false
+// when it doesn't know the kind of subject and fell back to something
+// close, like the repo's homepage.
+func guessURL(apiURL string) (string, bool) {
 	const prefix = "https://api.github.com/repos/"
 	if !strings.HasPrefix(apiURL, prefix) {
-		return apiURL // unexpected but better safe than sorry
+		return apiURL, false // unexpected but better safe than sorry
 	}
 
 	path := strings.TrimPrefix(apiURL, prefix)
 	parts := strings.Split(path, "/")
 	if len(parts) < 3 {
-		return apiURL
+		return apiURL, false
 	}
 
 	owner, repo, kind := parts[0], parts[1], parts[2]
@@ -144,25 +669,25 @@ func uiURL(apiURL string) string {
 	switch kind {
 	case "pulls":
 		if len(parts) >= 4 {
-			return fmt.Sprintf("https://github.com/%s/pull/%s", repoPath, parts[3])
+			return fmt.Sprintf("https://github.com/%s/pull/%s", repoPath, parts[3]), true
 		}
 	case "issues":
 		if len(parts) >= 4 {
-			return fmt.Sprintf("https://github.com/%s/issues/%s", repoPath, parts[3])
+			return fmt.Sprintf("https://github.com/%s/issues/%s", repoPath, parts[3]), true
 		}
 	case "commits":
 		if len(parts) >= 4 {
-			return fmt.Sprintf("https://github.com/%s/commit/%s", repoPath, parts[3])
+			return fmt.Sprintf("https://github.com/%s/commit/%s", repoPath, parts[3]), true
 		}
 	case "releases":
 		if len(parts) >= 4 {
-			return fmt.Sprintf("https://github.com/%s/releases/%s", repoPath, parts[3])
+			return fmt.Sprintf("https://github.com/%s/releases/%s", repoPath, parts[3]), true
 		}
 	default:
 		// Covers events like "repository", "discussion", etc.
-		return fmt.Sprintf("https://github.com/%s", repoPath)
+		return fmt.Sprintf("https://github.com/%s", repoPath), false
 	}
 
 	// fallback to repo homepage if structure is unfamiliar
-	return fmt.Sprintf("https://github.com/%s", repoPath)
+	return fmt.Sprintf("https://github.com/%s", repoPath), false
 }
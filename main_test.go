@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v66/github"
+	"github.com/lukemassa/github-notification-manager/testutil"
+)
+
+// oldDisplayOrder is how the loop used to order notifications: sorted
+// newest first, reversed, then popped from the end.
+func oldDisplayOrder(notifications []*github.Notification) []*github.Notification {
+	notifications = slices.Clone(notifications)
+	sort.Slice(notifications, func(i, j int) bool {
+		return notifications[i].GetUpdatedAt().Time.After(notifications[j].GetUpdatedAt().Time)
+	})
+	slices.Reverse(notifications)
+	var shown []*github.Notification
+	for queue := notifications; len(queue) > 0; {
+		shown = append(shown, queue[len(queue)-1])
+		queue = queue[:len(queue)-1]
+	}
+	return shown
+}
+
+func TestSortNewestFirstGolden(t *testing.T) {
+	base := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	// In the order GitHub returned them. Small enough that sort.Slice
+	// insertion sorts, so the old order kept ties stable too. b, c and e
+	// tie, and so do d and f.
+	fixture := []*github.Notification{
+		testutil.NewNotification("a", "lukemassa/example", "a", base.Add(-3*time.Hour)),
+		testutil.NewNotification("b", "lukemassa/example", "b", base),
+		testutil.NewNotification("c", "lukemassa/example", "c", base),
+		testutil.NewNotification("d", "lukemassa/example", "d", base.Add(-time.Hour)),
+		testutil.NewNotification("e", "lukemassa/example", "e", base),
+		testutil.NewNotification("f", "lukemassa/example", "f", base.Add(-time.Hour)),
+		testutil.NewNotification("g", "lukemassa/example", "g", base.Add(time.Hour)),
+	}
+	golden := []string{"g", "b", "c", "e", "d", "f", "a"}
+
+	if got := notificationIDs(oldDisplayOrder(fixture)); !slices.Equal(got, golden) {
+		t.Fatalf("old order %v, want %v", got, golden)
+	}
+	sorted := slices.Clone(fixture)
+	sortNewestFirst(sorted)
+	if got := notificationIDs(sorted); !slices.Equal(got, golden) {
+		t.Errorf("sortNewestFirst order %v, want %v", got, golden)
+	}
+}
+
+func BenchmarkDisplayOrder(b *testing.B) {
+	base := time.Now()
+	fixture := make([]*github.Notification, 10000)
+	for i := range fixture {
+		// Out of order, with plenty of ties.
+		updated := base.Add(-time.Duration(i*7919%2000) * time.Minute)
+		fixture[i] = testutil.NewNotification(fmt.Sprint(i), "lukemassa/example", "Notification", updated)
+	}
+	// Both walk the whole queue, as the loop does, without keeping what
+	// they saw.
+	var last *github.Notification
+	b.Run("old", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			queue := slices.Clone(fixture)
+			sort.Slice(queue, func(i, j int) bool {
+				return queue[i].GetUpdatedAt().Time.After(queue[j].GetUpdatedAt().Time)
+			})
+			slices.Reverse(queue)
+			for len(queue) > 0 {
+				last, queue = queue[len(queue)-1], queue[:len(queue)-1]
+			}
+		}
+	})
+	b.Run("new", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			queue := slices.Clone(fixture)
+			sortNewestFirst(queue)
+			for len(queue) > 0 {
+				last, queue = queue[0], queue[1:]
+			}
+		}
+	})
+	_ = last
+}
+
+func TestEmptyMessage(t *testing.T) {
+	tests := []struct {
+		name              string
+		silent, unchanged bool
+		unread            int
+		want              string
+	}{
+		{"silent", true, false, 0, ""},
+		{"silent with hidden", true, false, 3, ""},
+		{"silent and unchanged", true, true, 0, ""},
+		{"no unread", false, false, 0, "🎉 No unread notifications!"},
+		{"unchanged", false, true, 0, "💤 No changes since the last run."},
+		{"hidden by filters", false, false, 3, "🎉 Nothing to triage (3 unread hidden by filters or snoozes)."},
+		{"unchanged and hidden by filters", false, true, 3, "🎉 Nothing to triage (3 unread hidden by filters or snoozes)."},
+	}
+	for _, tt := range tests {
+		if got := emptyMessage(tt.silent, tt.unchanged, tt.unread); got != tt.want {
+			t.Errorf("%s: emptyMessage(%v, %v, %d) = %q, want %q", tt.name, tt.silent, tt.unchanged, tt.unread, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// writeMarkdown prints a GitHub-flavored Markdown table, for pasting into an
+// issue or a doc to track a backlog.
+func writeMarkdown(w io.Writer, summaries []NotificationSummary) error {
+	if _, err := fmt.Fprintln(w, "| Status | Title | Repo | Type | Updated |\n| --- | --- | --- | --- | --- |"); err != nil {
+		return err
+	}
+	for _, s := range summaries {
+		status := "read"
+		if s.Unread {
+			status = "unread"
+		}
+		title := fmt.Sprintf("[%s](%s)", markdownEscape(s.Title), s.URL)
+		_, err := fmt.Fprintf(w, "| %s | %s | %s | %s | %s |\n",
+			status, title, markdownEscape(s.Repo), s.Type, s.UpdatedAt.Format(time.DateTime))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// markdownEscaper escapes what would break a table cell or link text.
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "|", `\|`, "[", `\[`, "]", `\]`, "\n", " ")
+
+func markdownEscape(s string) string {
+	return markdownEscaper.Replace(s)
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+)
+
+// markReadByID marks the given threads read without fetching the inbox, for
+// IDs taken from the json, csv or list output. Each thread is looked up first
+// so safe_repos applies and the history has its title.
+func markReadByID(ctx context.Context, t *triager, ids []string) int {
+	status := exitOK
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			return exitStatus(ctx.Err())
+		}
+		n, _, err := t.client.Activity.GetThread(ctx, id)
+		if err != nil {
+			log.Printf("⚠️  Failed to look up thread %s: %v\n", id, err)
+			status = exitStatus(err)
+			continue
+		}
+		if !t.bulkRead(ctx, n) {
+			status = exitError
+			continue
+		}
+		t.say(fmt.Sprintf("✅ Marked %s read: %s", id, n.GetSubject().GetTitle()))
+	}
+	return status
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// netrcPath is $NETRC, or ~/.netrc.
+func netrcPath() (string, error) {
+	if path := os.Getenv("NETRC"); path != "" {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".netrc"), nil
+}
+
+// netrcToken returns the password for host in the netrc file, falling back
+// to the "default" entry. A missing file or host is an empty token, not an
+// error.
+func netrcToken(host string) (string, error) {
+	path, err := netrcPath()
+	if err != nil {
+		return "", err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	var (
+		machine, fallback  string
+		inEntry, isDefault bool
+		inMacro            bool
+	)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		// A macro runs until the next blank line.
+		if inMacro {
+			inMacro = strings.TrimSpace(line) != ""
+			continue
+		}
+		if before, _, ok := strings.Cut(line, "#"); ok {
+			line = before
+		}
+		fields := strings.Fields(line)
+		for i := 0; i < len(fields); i++ {
+			var value string
+			if i+1 < len(fields) {
+				value = fields[i+1]
+			}
+			switch fields[i] {
+			case "machine":
+				machine, inEntry, isDefault = value, true, false
+				i++
+			case "default":
+				machine, inEntry, isDefault = "", true, true
+			case "login", "account":
+				i++
+			case "password":
+				i++
+				switch {
+				case !inEntry:
+				case strings.EqualFold(machine, host):
+					return value, scanner.Err()
+				case isDefault && fallback == "":
+					fallback = value
+				}
+			case "macdef":
+				inMacro = true
+				i = len(fields)
+			}
+		}
+	}
+	return fallback, scanner.Err()
+}
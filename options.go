@@ -0,0 +1,388 @@
+package main
+
+import (
+	"cmp"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v66/github"
+)
+
+// Options are the flags shared by the triage run and anything that needs to
+// know what a run would do, like `config show`.
+type Options struct {
+	ConfigPath       string
+	Profile          string
+	Participating    bool
+	All              bool
+	Since            Age
+	OnlyRead         bool
+	OnlyUnread       bool
+	Reason           string
+	OnlyMentions     bool
+	Conditional      bool
+	AutoDeps         bool
+	AutoStateChange  bool
+	StaleAfter       Age
+	AutoReadStale    bool
+	SkipStale        bool
+	MaxAge           Age
+	MinAge           Age
+	SkipReleases     bool
+	SkipCheckSuites  bool
+	AutoReadReleases bool
+	OnlyOpen         bool
+	Involves         string
+	CACert           string
+	Insecure         bool
+	NoAutoApprove    bool
+	Types            string
+	Filter           string
+	CIFailed         bool
+	UseGraphQL       bool
+	RetryNetwork     bool
+	MaxRetries       int
+	RateLimitBuffer  int
+	ResolveURLs      bool
+	GitHubToken      string
+	FormatTitle      string
+	FormatDetail     string
+	FormatPrompt     string
+	Labels           string
+	ExcludeLabels    string
+	IgnoreDrafts     bool
+	AutoReadDrafts   bool
+	IgnoreClosed     bool
+	AutoDraftCI      bool
+	AutoReadMerged   bool
+	AutoReadCI       bool
+}
+
+func (o *Options) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.ConfigPath, "config", defaultConfigPath(), "path to the YAML config file")
+	fs.StringVar(&o.GitHubToken, "github-token", "", "GitHub token to use (overrides GITHUB_TOKEN and the config)")
+	fs.StringVar(&o.Profile, "profile", "", "config profile to use (defaults to default_profile)")
+	fs.BoolVar(&o.Participating, "participating", false, "only threads you're directly participating in")
+	fs.BoolVar(&o.All, "all", false, "fetch notifications already marked read too, not just unread ones")
+	fs.Var(&o.Since, "since", "only notifications updated in this long (e.g. 24h), asked of GitHub rather than filtered afterwards (short: -s)")
+	fs.BoolVar(&o.OnlyRead, "only-read", false, "with -all, only notifications already marked read")
+	fs.BoolVar(&o.OnlyUnread, "only-unread", false, "with -all, only notifications still unread")
+	fs.StringVar(&o.Reason, "reason", "", "comma-separated notification reasons to keep (e.g. mention,review_requested) (short: -r)")
+	fs.BoolVar(&o.OnlyMentions, "only-mentions", false, "shorthand for -reason mention -participating; conflicts with any other -reason")
+	fs.BoolVar(&o.AutoDeps, "auto-deps", false, "auto-approve dependency bumps from both renovate and dependabot")
+	fs.BoolVar(&o.AutoStateChange, "auto-state-change", false, "auto-approve notifications that are only about a thread being merged or closed")
+	fs.BoolVar(&o.AutoReadCI, "auto-read-ci-activity", false, "auto-approve notifications whose reason is ci_activity (workflow runs you triggered)")
+	fs.Var(&o.StaleAfter, "stale-after", "mark notifications not updated in this long (e.g. 7d) as stale")
+	fs.BoolVar(&o.AutoReadStale, "auto-read-stale", false, "mark stale notifications read without asking")
+	fs.BoolVar(&o.SkipStale, "skip-stale", false, "skip stale notifications without marking them read")
+	fs.Var(&o.MaxAge, "max-age", "ignore notifications not updated in this long (e.g. 30d)")
+	fs.Var(&o.MinAge, "min-age", "ignore notifications updated more recently than this (e.g. 1h), to let discussions settle")
+	fs.BoolVar(&o.SkipReleases, "skip-releases", false, "skip release notifications without marking them read")
+	fs.BoolVar(&o.SkipCheckSuites, "skip-check-suite", false, "skip check suite (CI) notifications without marking them read")
+	fs.BoolVar(&o.AutoReadReleases, "auto-read-releases", false, "mark all release notifications read before the interactive session")
+	fs.BoolVar(&o.IgnoreDrafts, "ignore-draft-prs", false, "skip draft pull requests without marking them read (looks each one up)")
+	fs.BoolVar(&o.AutoReadDrafts, "auto-read-draft-prs", false, "mark draft pull requests read without asking (looks each one up)")
+	fs.BoolVar(&o.IgnoreClosed, "ignore-closed", false, "skip notifications for issues and pull requests that are already closed or merged, without marking them read (looks each one up)")
+	fs.BoolVar(&o.AutoDraftCI, "auto-draft-ci", false, "auto-approve CI (check suite) notifications for your own draft pull requests")
+	fs.BoolVar(&o.AutoReadMerged, "auto-read-merged", false, "auto-approve notifications for merged pull requests (looks each one up)")
+	fs.BoolVar(&o.OnlyOpen, "only-open", false, "drop pull requests that are already merged or closed (looks each one up)")
+	fs.StringVar(&o.Involves, "involves", "", "only threads this user is involved in (author, assignee, reviewer or commenter; costs extra API requests)")
+	fs.StringVar(&o.Labels, "label", "", "only PRs and issues with any of these comma-separated labels (looks each one up)")
+	fs.StringVar(&o.ExcludeLabels, "exclude-label", "", "drop PRs and issues with any of these comma-separated labels (looks each one up)")
+	fs.StringVar(&o.CACert, "ca-cert", "", "PEM file of extra CA certificates to trust (e.g. a corporate proxy CA)")
+	fs.BoolVar(&o.Insecure, "insecure", false, "skip TLS certificate verification (dangerous; last resort behind a broken proxy)")
+	fs.BoolVar(&o.NoAutoApprove, "no-auto-approve", false, "turn off every auto-approval rule for this run and review everything yourself")
+	fs.StringVar(&o.Types, "type", "", "comma-separated subject types to keep (e.g. PullRequest,Issue) (short: -t)")
+	fs.StringVar(&o.Filter, "filter", "", "apply a named filter from the config")
+	fs.BoolVar(&o.CIFailed, "ci-failed", false, "only failed CI runs and pull requests with failing checks (looks up each PR's checks)")
+	fs.BoolVar(&o.UseGraphQL, "use-graphql", false, "look up PRs and issues for -only-open, -involves and -ci-failed in batched GraphQL queries instead of one REST request each")
+	fs.BoolVar(&o.RetryNetwork, "retry-on-network-error", false, "retry fetching on network errors (not API errors) with backoff")
+	fs.IntVar(&o.MaxRetries, "max-retries", 3, "how many times -retry-on-network-error retries")
+	fs.IntVar(&o.RateLimitBuffer, "rate-limit-buffer", 0, "stop once fewer than this many API requests are left in the hour, e.g. 200 (0 means no limit)")
+	fs.BoolVar(&o.ResolveURLs, "resolve-urls", false, "look up the web URL of subjects (e.g. check suites) that can't be worked out from their API URL")
+	fs.StringVar(&o.FormatTitle, "format-title", "", "text/template for each notification's first line, overriding display.header (e.g. '{{.Subject.Title}} [{{.Repository.FullName}}]')")
+	fs.StringVar(&o.FormatDetail, "format-detail", "", "text/template for the lines under each notification's first line (Repo, Type, URL, Updated); see the README for what it can use")
+	// Short aliases share the long flag's variable, so either spelling works.
+	fs.StringVar(&o.Reason, "r", "", "shorthand for -reason")
+	fs.StringVar(&o.Types, "t", "", "shorthand for -type")
+	fs.Var(&o.Since, "s", "shorthand for -since")
+	fs.StringVar(&o.FormatPrompt, "format-prompt", "", "text/template for -output prompt, overriding display.prompt (e.g. '{{.Total}} {{with .Reason \"review_requested\"}}R:{{.}}{{end}}')")
+	fs.BoolVar(&o.Conditional, "conditional", false, "reuse the last finished run's listing for repos that haven't changed since (uses If-Modified-Since)")
+}
+
+// Settings is the effective configuration for a run: the config file, the
+// environment and the flags merged together.
+type Settings struct {
+	ConfigPath       string   `json:"config_path" yaml:"config_path"`
+	Token            string   `json:"token" yaml:"token"`
+	TokenSource      string   `json:"token_source" yaml:"token_source"`
+	Profile          string   `json:"profile,omitempty" yaml:"profile,omitempty"`
+	Repos            []string `json:"repos" yaml:"repos"`
+	Participating    bool     `json:"participating" yaml:"participating"`
+	All              bool     `json:"all" yaml:"all"`
+	Since            Age      `json:"since,omitempty" yaml:"since,omitempty"`
+	OnlyRead         bool     `json:"only_read" yaml:"only_read"`
+	OnlyUnread       bool     `json:"only_unread" yaml:"only_unread"`
+	Reasons          []string `json:"reasons,omitempty" yaml:"reasons,omitempty"`
+	Conditional      bool     `json:"conditional" yaml:"conditional"`
+	StaleAfter       Age      `json:"stale_after,omitempty" yaml:"stale_after,omitempty"`
+	AutoReadStale    bool     `json:"auto_read_stale" yaml:"auto_read_stale"`
+	SkipStale        bool     `json:"skip_stale" yaml:"skip_stale"`
+	MaxAge           Age      `json:"max_age,omitempty" yaml:"max_age,omitempty"`
+	MinAge           Age      `json:"min_age,omitempty" yaml:"min_age,omitempty"`
+	SkipReleases     bool     `json:"skip_releases" yaml:"skip_releases"`
+	SkipCheckSuites  bool     `json:"skip_check_suites" yaml:"skip_check_suites"`
+	AutoReadReleases bool     `json:"auto_read_releases" yaml:"auto_read_releases"`
+	OnlyOpen         bool     `json:"only_open" yaml:"only_open"`
+	Involves         string   `json:"involves,omitempty" yaml:"involves,omitempty"`
+	Labels           []string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	ExcludeLabels    []string `json:"exclude_labels,omitempty" yaml:"exclude_labels,omitempty"`
+	Separator        string   `json:"separator" yaml:"separator"`
+	Header           string   `json:"header" yaml:"header"`
+	Detail           string   `json:"detail" yaml:"detail"`
+	Prompt           string   `json:"prompt" yaml:"prompt"`
+	CACert           string   `json:"ca_cert,omitempty" yaml:"ca_cert,omitempty"`
+	Insecure         bool     `json:"insecure" yaml:"insecure"`
+	NoAutoApprove    bool     `json:"no_auto_approve" yaml:"no_auto_approve"`
+	Filter           string   `json:"filter,omitempty" yaml:"filter,omitempty"`
+	Types            []string `json:"types,omitempty" yaml:"types,omitempty"`
+	CIFailed         bool     `json:"ci_failed" yaml:"ci_failed"`
+	UseGraphQL       bool     `json:"use_graphql" yaml:"use_graphql"`
+	RetryNetwork     bool     `json:"retry_on_network_error" yaml:"retry_on_network_error"`
+	MaxRetries       int      `json:"max_retries" yaml:"max_retries"`
+	RateLimitBuffer  int      `json:"rate_limit_buffer,omitempty" yaml:"rate_limit_buffer,omitempty"`
+	ResolveURLs      bool     `json:"resolve_urls" yaml:"resolve_urls"`
+	SafeRepos        []string `json:"safe_repos,omitempty" yaml:"safe_repos,omitempty"`
+	IgnoreDrafts     bool     `json:"ignore_draft_prs" yaml:"ignore_draft_prs"`
+	AutoReadDrafts   bool     `json:"auto_read_draft_prs" yaml:"auto_read_draft_prs"`
+	IgnoreClosed     bool     `json:"ignore_closed" yaml:"ignore_closed"`
+	Rules            []*Rule  `json:"rules" yaml:"rules"`
+
+	// details are subject details by thread ID, filled in by
+	// applyEnrichedFilters when decide needs them.
+	details map[string]*subjectDetails
+}
+
+// stale reports whether n hasn't been updated within StaleAfter.
+func (s *Settings) stale(n *github.Notification, now time.Time) bool {
+	return s.StaleAfter > 0 && now.Sub(n.GetUpdatedAt().Time) > time.Duration(s.StaleAfter)
+}
+
+// resolve merges the config file, environment and flags. Precedence for the
+// token is -github-token, GITHUB_TOKEN, the profile, the top level of the
+// config, and finally an api.github.com entry in ~/.netrc.
+func (o *Options) resolve() (*Settings, error) {
+	s := &Settings{
+		ConfigPath:       o.ConfigPath,
+		Participating:    o.Participating,
+		All:              o.All,
+		Since:            o.Since,
+		OnlyRead:         o.OnlyRead,
+		OnlyUnread:       o.OnlyUnread,
+		Conditional:      o.Conditional,
+		StaleAfter:       o.StaleAfter,
+		AutoReadStale:    o.AutoReadStale,
+		SkipStale:        o.SkipStale,
+		MaxAge:           o.MaxAge,
+		MinAge:           o.MinAge,
+		SkipReleases:     o.SkipReleases,
+		SkipCheckSuites:  o.SkipCheckSuites,
+		AutoReadReleases: o.AutoReadReleases,
+		OnlyOpen:         o.OnlyOpen,
+		Involves:         o.Involves,
+		CACert:           o.CACert,
+		Insecure:         o.Insecure,
+		NoAutoApprove:    o.NoAutoApprove,
+		Filter:           o.Filter,
+		CIFailed:         o.CIFailed,
+		UseGraphQL:       o.UseGraphQL,
+		RetryNetwork:     o.RetryNetwork,
+		MaxRetries:       o.MaxRetries,
+		RateLimitBuffer:  o.RateLimitBuffer,
+		ResolveURLs:      o.ResolveURLs,
+		IgnoreDrafts:     o.IgnoreDrafts,
+		AutoReadDrafts:   o.AutoReadDrafts,
+		IgnoreClosed:     o.IgnoreClosed,
+	}
+	if o.MaxRetries < 0 {
+		return nil, fmt.Errorf("-max-retries can't be negative")
+	}
+	if (o.OnlyRead || o.OnlyUnread) && !o.All {
+		return nil, fmt.Errorf("-only-read and -only-unread need -all")
+	}
+	if o.OnlyRead && o.OnlyUnread {
+		return nil, fmt.Errorf("-only-read and -only-unread conflict")
+	}
+	if o.RateLimitBuffer < 0 {
+		return nil, fmt.Errorf("-rate-limit-buffer can't be negative")
+	}
+	if o.AutoReadReleases && o.SkipReleases {
+		return nil, fmt.Errorf("-auto-read-releases and -skip-releases conflict")
+	}
+	if (o.AutoReadStale || o.SkipStale) && o.StaleAfter == 0 {
+		return nil, fmt.Errorf("-auto-read-stale and -skip-stale need -stale-after")
+	}
+	if o.IgnoreDrafts && o.AutoReadDrafts {
+		return nil, fmt.Errorf("-ignore-draft-prs and -auto-read-draft-prs conflict")
+	}
+	if o.AutoReadStale && o.SkipStale {
+		return nil, fmt.Errorf("-auto-read-stale and -skip-stale conflict")
+	}
+
+	cfg, err := loadConfig(o.ConfigPath)
+	if err != nil {
+		return nil, configError{fmt.Errorf("loading config: %w", err)}
+	}
+	if errs := cfg.validate(); len(errs) > 0 {
+		return nil, configError{fmt.Errorf("invalid config (run `config validate` for details): %w", errs[0])}
+	}
+
+	// A named filter fills in whichever filter flags weren't given.
+	reason, types := o.Reason, o.Types
+	if o.Filter != "" {
+		f, ok := cfg.Filters[o.Filter]
+		if !ok {
+			return nil, configError{fmt.Errorf("no filter named %q in the config", o.Filter)}
+		}
+		if reason == "" {
+			reason = f.Reason
+		}
+		if types == "" {
+			types = f.Type
+		}
+		s.Participating = s.Participating || f.Participating
+		if s.MaxAge == 0 && f.MaxAge != "" {
+			d, _ := parseAge(f.MaxAge) // checked by validate
+			s.MaxAge = Age(d)
+		}
+	}
+	if s.MinAge > 0 && s.MaxAge > 0 && s.MinAge >= s.MaxAge {
+		return nil, fmt.Errorf("-min-age %s leaves nothing under -max-age %s", &s.MinAge, &s.MaxAge)
+	}
+	if types != "" {
+		s.Types = splitList(types)
+	}
+
+	if o.OnlyMentions {
+		if reason != "" && reason != "mention" {
+			return nil, fmt.Errorf("-only-mentions conflicts with -reason %s", reason)
+		}
+		reason = "mention"
+		s.Participating = true
+	}
+	if reason != "" {
+		s.Reasons = splitList(reason)
+	}
+	s.Labels, s.ExcludeLabels = splitList(o.Labels), splitList(o.ExcludeLabels)
+	profile, p, err := cfg.activeProfile(o.Profile)
+	if err != nil {
+		return nil, err
+	}
+	s.Profile = profile
+	s.Separator, s.Header = defaultSeparator, defaultHeader
+	if cfg.Display.Separator != "" {
+		s.Separator = cfg.Display.Separator
+	}
+	if cfg.Display.Header != "" {
+		s.Header = cfg.Display.Header
+	}
+	if o.FormatTitle != "" {
+		if _, err := parseHeader(o.FormatTitle); err != nil {
+			return nil, fmt.Errorf("-format-title: %w", err)
+		}
+		s.Header = o.FormatTitle
+	}
+	s.Detail = defaultDetail
+	if o.FormatDetail != "" {
+		if _, err := parseDetail(o.FormatDetail); err != nil {
+			return nil, fmt.Errorf("-format-detail: %w", err)
+		}
+		// Each notification's block ends with a newline, as the default does.
+		s.Detail = strings.TrimSuffix(o.FormatDetail, "\n") + "\n"
+	}
+	s.Prompt = cmp.Or(cfg.Display.Prompt, defaultPrompt)
+	if o.FormatPrompt != "" {
+		if _, err := parsePrompt(o.FormatPrompt); err != nil {
+			return nil, fmt.Errorf("-format-prompt: %w", err)
+		}
+		s.Prompt = o.FormatPrompt
+	}
+	s.Rules = cfg.rules(profile)
+	if o.AutoDeps {
+		s.Rules = addRules(s.Rules, depRules())
+	}
+	if o.AutoStateChange {
+		s.Rules = addRules(s.Rules, builtin("state-change"))
+	}
+	if o.AutoReadMerged {
+		s.Rules = addRules(s.Rules, builtin("merged"))
+	}
+	if o.AutoReadCI {
+		s.Rules = addRules(s.Rules, builtin("ci-activity"))
+	}
+	if o.AutoDraftCI {
+		s.Rules = addRules(s.Rules, builtin("draft-ci"))
+	}
+	if o.NoAutoApprove {
+		if o.AutoDeps || o.AutoStateChange || o.AutoReadStale || o.AutoReadReleases || o.AutoReadDrafts || o.AutoDraftCI || o.AutoReadMerged || o.AutoReadCI {
+			return nil, fmt.Errorf("-no-auto-approve conflicts with the -auto-* flags")
+		}
+		s.Rules = slices.DeleteFunc(s.Rules, func(r *Rule) bool { return r.Action == "mark-read" })
+	}
+
+	s.SafeRepos = cfg.SafeRepos
+	s.Repos = defaultRepos
+	if len(cfg.Repos) > 0 {
+		s.Repos = cfg.Repos
+	}
+	if p != nil && len(p.Repos) > 0 {
+		s.Repos = p.Repos
+	}
+
+	switch {
+	case o.GitHubToken != "":
+		s.Token, s.TokenSource = o.GitHubToken, "-github-token"
+	case os.Getenv("GITHUB_TOKEN") != "":
+		s.Token, s.TokenSource = os.Getenv("GITHUB_TOKEN"), "GITHUB_TOKEN"
+	case p != nil && p.Token != "":
+		s.Token, s.TokenSource = p.Token, "profiles."+profile+".token"
+	case cfg.Token != "":
+		s.Token, s.TokenSource = cfg.Token, "config token"
+	default:
+		token, err := netrcToken("api.github.com")
+		if err != nil {
+			return nil, configError{fmt.Errorf("reading .netrc: %w", err)}
+		}
+		if token != "" {
+			path, _ := netrcPath()
+			s.Token, s.TokenSource = token, path
+			log.Printf("🔑 Using the api.github.com token from %s\n", path)
+		}
+	}
+	return s, nil
+}
+
+// splitList splits a comma-separated flag value, trimming spaces.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// redacted returns a copy safe to print.
+func (s *Settings) redacted() *Settings {
+	c := *s
+	if c.Token != "" {
+		c.Token = "***"
+	}
+	return &c
+}
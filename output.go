@@ -0,0 +1,212 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/go-github/v66/github"
+)
+
+// outputFormats are the values -output accepts besides "interactive".
+var outputFormats = []string{"json", "csv", "stats", "html", "list", "markdown", "ical", "prompt"}
+
+// NotificationSummary is the flattened view of a notification used by the
+// non-interactive outputs.
+type NotificationSummary struct {
+	// ID is the thread ID, as -mark-read and the subscriptions subcommand
+	// take it.
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	Repo      string    `json:"repo"`
+	Type      string    `json:"type"`
+	Reason    string    `json:"reason"`
+	URL       string    `json:"url"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Unread    bool      `json:"unread"`
+}
+
+func summarize(n *github.Notification) NotificationSummary {
+	return NotificationSummary{
+		ID:        n.GetID(),
+		Title:     n.GetSubject().GetTitle(),
+		Repo:      n.GetRepository().GetFullName(),
+		Type:      n.GetSubject().GetType(),
+		Reason:    n.GetReason(),
+		URL:       uiURL(n.GetSubject().GetURL()),
+		UpdatedAt: n.GetUpdatedAt().Time,
+		Unread:    n.GetUnread(),
+	}
+}
+
+// summarizeAll summarizes notifications in the order given.
+func summarizeAll(notifications []*github.Notification) []NotificationSummary {
+	summaries := []NotificationSummary{}
+	for _, n := range notifications {
+		summaries = append(summaries, summarize(n))
+	}
+	return summaries
+}
+
+// writeOutput renders notifications, in the order given, in the given format.
+// The settings are for formats that can be customized, like prompt.
+func writeOutput(w io.Writer, format string, notifications []*github.Notification, settings *Settings) error {
+	summaries := summarizeAll(notifications)
+
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(summaries)
+	case "csv":
+		return writeCSV(w, summaries)
+	case "stats":
+		return writeStats(w, summaries)
+	case "html":
+		return writeHTML(w, summaries)
+	case "list":
+		return writeList(w, summaries)
+	case "markdown":
+		return writeMarkdown(w, summaries)
+	case "ical":
+		return writeICal(w, summaries)
+	case "prompt":
+		return writePrompt(w, settings.Prompt, summaries)
+	default:
+		return fmt.Errorf("unknown output format %q", format)
+	}
+}
+
+func writeCSV(w io.Writer, summaries []NotificationSummary) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"id", "title", "repo", "type", "reason", "url", "updated_at"})
+	for _, s := range summaries {
+		cw.Write([]string{s.ID, s.Title, s.Repo, s.Type, s.Reason, s.URL, s.UpdatedAt.Format(time.RFC3339)})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeStats prints counts by repo, type and reason, and a histogram of how
+// long ago notifications were updated.
+func writeStats(w io.Writer, summaries []NotificationSummary) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Total\t%d\n", len(summaries))
+	sections := []struct {
+		name string
+		key  func(NotificationSummary) string
+	}{
+		{"Repo", func(s NotificationSummary) string { return s.Repo }},
+		{"Type", func(s NotificationSummary) string { return s.Type }},
+		{"Reason", func(s NotificationSummary) string { return s.Reason }},
+	}
+	for _, section := range sections {
+		fmt.Fprintf(tw, "\n%s\tCount\n", section.name)
+		for _, c := range countBy(summaries, section.key) {
+			fmt.Fprintf(tw, "%s\t%d\n", c.key, c.count)
+		}
+	}
+	fmt.Fprintf(tw, "\nAge\tCount\n")
+	for _, b := range ageHistogram(summaries, time.Now()) {
+		fmt.Fprintf(tw, "%s\t%d\t%s\n", b.label, b.count, b.bar)
+	}
+	return tw.Flush()
+}
+
+// ageBuckets are the histogram's buckets, by upper bound; anything older
+// falls in the last one.
+var ageBuckets = []struct {
+	label string
+	under time.Duration
+}{
+	{"<1h", time.Hour},
+	{"<1d", 24 * time.Hour},
+	{"<1w", 7 * 24 * time.Hour},
+	{"<1mo", 30 * 24 * time.Hour},
+	{"older", 0},
+}
+
+// maxBar is the width of the longest histogram bar.
+const maxBar = 40
+
+type ageBucket struct {
+	label string
+	count int
+	bar   string
+}
+
+// ageHistogram counts summaries by age bucket, with a bar for each scaled so
+// the fullest bucket is maxBar wide.
+func ageHistogram(summaries []NotificationSummary, now time.Time) []ageBucket {
+	buckets := make([]ageBucket, len(ageBuckets))
+	for i, b := range ageBuckets {
+		buckets[i].label = b.label
+	}
+	most := 0
+	for _, s := range summaries {
+		age := now.Sub(s.UpdatedAt)
+		i := len(ageBuckets) - 1
+		for j, b := range ageBuckets[:i] {
+			if age < b.under {
+				i = j
+				break
+			}
+		}
+		buckets[i].count++
+		most = max(most, buckets[i].count)
+	}
+	for i := range buckets {
+		if c := buckets[i].count; c > 0 {
+			// At least one block so a non-empty bucket is visible.
+			buckets[i].bar = strings.Repeat("█", max(1, c*maxBar/most))
+		}
+	}
+	return buckets
+}
+
+type keyCount struct {
+	key   string
+	count int
+}
+
+// countBy tallies summaries by key, largest first.
+func countBy(summaries []NotificationSummary, key func(NotificationSummary) string) []keyCount {
+	counts := map[string]int{}
+	for _, s := range summaries {
+		counts[key(s)]++
+	}
+	var result []keyCount
+	for k, c := range counts {
+		result = append(result, keyCount{k, c})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].count != result[j].count {
+			return result[i].count > result[j].count
+		}
+		return result[i].key < result[j].key
+	})
+	return result
+}
+
+// writeOutputTo writes to path (created or truncated), or stdout if path is empty.
+func writeOutputTo(path, format string, notifications []*github.Notification, settings *Settings) (err error) {
+	if path == "" {
+		return writeOutput(os.Stdout, format, notifications, settings)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}()
+	return writeOutput(f, format, notifications, settings)
+}
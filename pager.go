@@ -0,0 +1,47 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// pagerCommand resolves the -pager value: "auto" means $PAGER, or less if
+// that isn't set.
+func pagerCommand(flagValue string) string {
+	if flagValue != "auto" {
+		return flagValue
+	}
+	if p := os.Getenv("PAGER"); p != "" {
+		return p
+	}
+	return "less"
+}
+
+// writePaged runs write with its output piped through the pager command. The
+// command goes through sh so a PAGER like "less -R" works.
+func writePaged(command string, write func(io.Writer) error) error {
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("starting pager: %w", err)
+	}
+	werr := write(stdin)
+	stdin.Close()
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("pager: %w", err)
+	}
+	// Quitting the pager early closes the pipe; that's not worth reporting.
+	if werr != nil && !errors.Is(werr, syscall.EPIPE) {
+		return werr
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// prompter reads answers from stdin on a separate goroutine so that a
+// pending prompt doesn't keep Ctrl-C from unwinding the session.
+type prompter struct {
+	lines chan string
+}
+
+func newPrompter(r io.Reader) *prompter {
+	p := &prompter{lines: make(chan string)}
+	go func() {
+		defer close(p.lines)
+		reader := bufio.NewReader(r)
+		for {
+			text, err := reader.ReadString('\n')
+			if text != "" || err == nil {
+				p.lines <- text
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return p
+}
+
+// ask prints question and returns the trimmed answer. At end of input the
+// answer is empty. It returns the context's error if it's cancelled first.
+func (p *prompter) ask(ctx context.Context, question string) (string, error) {
+	fmt.Print(question)
+	select {
+	case <-ctx.Done():
+		fmt.Println()
+		return "", ctx.Err()
+	case text := <-p.lines:
+		return strings.TrimSpace(text), nil
+	}
+}
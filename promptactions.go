@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/google/go-github/v66/github"
+)
+
+// promptAction is something the interactive prompt accepts. The prompt, the
+// "?" help and the dispatch are all built from the list, so they agree.
+type promptAction struct {
+	key, name string
+	help      string
+	// show is set for actions that only show something; the prompt is asked
+	// again afterwards.
+	show bool
+	// hidden, if set, keeps the action out of the prompt line (not the help)
+	// while it returns true.
+	hidden func() bool
+	run    func(n *github.Notification)
+}
+
+type promptActions []promptAction
+
+// find returns the action answer picks, by key or name, or nil to skip.
+func (as promptActions) find(answer string) *promptAction {
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	for i := range as {
+		if answer == as[i].key || answer == as[i].name {
+			return &as[i]
+		}
+	}
+	return nil
+}
+
+// question is the prompt line, e.g. "Mark as read? [y/N/a=archive/...]: ".
+// The first action is the "yes"; anything unrecognized is the "No".
+func (as promptActions) question(extra func(a promptAction) string) string {
+	var b strings.Builder
+	b.WriteString("Mark as read? [")
+	for i, a := range as {
+		if a.hidden != nil && a.hidden() {
+			continue
+		}
+		if i == 0 {
+			b.WriteString(a.key + "/N")
+			continue
+		}
+		b.WriteString("/" + a.key + "=" + a.name)
+		if extra != nil {
+			b.WriteString(extra(a))
+		}
+	}
+	b.WriteString("]: ")
+	return b.String()
+}
+
+func (as promptActions) printHelp() {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, a := range as {
+		fmt.Fprintf(tw, "   %s, %s\t%s\n", a.key, a.name, a.help)
+	}
+	fmt.Fprintf(tw, "   n, Enter\tskip (anything else skips too)\n")
+	tw.Flush()
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rateLimitGuard implements -rate-limit-buffer. It remembers the remaining
+// count GitHub reports on each response, per rate limit resource, and once
+// one falls below the buffer it refuses further requests against that
+// resource until the limit resets, leaving the rest of the hour's budget for
+// other tools.
+type rateLimitGuard struct {
+	base   http.RoundTripper
+	buffer int
+
+	mu        sync.Mutex
+	remaining map[string]int
+	reset     map[string]time.Time
+	// low is the first refusal, kept so the session can stop on it.
+	low *rateLimitLowError
+}
+
+// rateLimitLowError is what requests fail with once the guard trips.
+type rateLimitLowError struct {
+	resource  string
+	remaining int
+	buffer    int
+	reset     time.Time
+}
+
+func (e *rateLimitLowError) Error() string {
+	return fmt.Sprintf("stopping: only %d %s API requests left, below -rate-limit-buffer %d (resets at %s)",
+		e.remaining, e.resource, e.buffer, e.reset.Local().Format(time.Kitchen))
+}
+
+func (g *rateLimitGuard) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := g.check(requestResource(req)); err != nil {
+		return nil, err
+	}
+	resp, err := g.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	g.record(resp)
+	return resp, nil
+}
+
+func (g *rateLimitGuard) check(resource string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	left, known := g.remaining[resource]
+	reset := g.reset[resource]
+	if !known || left >= g.buffer || time.Now().After(reset) {
+		return nil
+	}
+	err := &rateLimitLowError{resource: resource, remaining: left, buffer: g.buffer, reset: reset}
+	if g.low == nil {
+		g.low = err
+	}
+	return err
+}
+
+func (g *rateLimitGuard) record(resp *http.Response) {
+	left, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return
+	}
+	resource := resp.Header.Get("X-RateLimit-Resource")
+	if resource == "" {
+		resource = requestResource(resp.Request)
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.remaining == nil {
+		g.remaining = map[string]int{}
+		g.reset = map[string]time.Time{}
+	}
+	g.remaining[resource] = left
+	g.reset[resource] = time.Unix(reset, 0)
+}
+
+// tripped returns the first request the guard refused, if any. It's safe to
+// call on a nil guard, which is what newClient leaves when there's no buffer.
+func (g *rateLimitGuard) tripped() error {
+	if g == nil {
+		return nil
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.low == nil {
+		return nil
+	}
+	return g.low
+}
+
+// requestResource guesses which rate limit a request counts against, before
+// GitHub says so in its response.
+func requestResource(req *http.Request) string {
+	switch {
+	case req == nil:
+		return "core"
+	case strings.HasSuffix(req.URL.Path, "/graphql"):
+		return "graphql"
+	case strings.HasPrefix(strings.TrimPrefix(req.URL.Path, "/api/v3"), "/search/"):
+		return "search"
+	}
+	return "core"
+}
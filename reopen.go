@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/google/go-github/v66/github"
+)
+
+// runReopen implements `reopen <notification-id>`, which tries to undo
+// whatever we last did to a notification.
+//
+// GitHub's API has no way to mark a thread unread again, so for threads we
+// marked read the best we can do is resubscribe and point at the web UI,
+// where "Mark as unread" exists.
+func runReopen(args []string) int {
+	fs := flag.NewFlagSet("reopen", flag.ExitOnError)
+	var opts Options
+	opts.register(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: github-notification-manager reopen [flags] <notification-id>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return exitError
+	}
+	id := fs.Arg(0)
+
+	state, err := loadState()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ loading state: %v\n", err)
+		return exitError
+	}
+	entry, ok := state.lastAction(id)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "❌ no record of notification %s in the local history\n", id)
+		return exitError
+	}
+
+	if entry.Action == "snoozed" {
+		delete(state.Snoozed, id)
+	} else {
+		settings, err := opts.resolve()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			return exitStatus(err)
+		}
+		if settings.Token == "" {
+			fmt.Fprintln(os.Stderr, "❌ GITHUB_TOKEN environment variable (or a token in the config) is required")
+			return exitAuth
+		}
+		ctx := context.Background()
+		client, _, err := newClient(ctx, settings)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			return exitStatus(err)
+		}
+		if err := reopenThread(ctx, client, id); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			return exitStatus(err)
+		}
+	}
+
+	n := &github.Notification{
+		ID:         github.String(id),
+		Subject:    &github.NotificationSubject{Title: github.String(entry.Title)},
+		Repository: &github.Repository{FullName: github.String(entry.Repo)},
+	}
+	state.record(n, "reopened")
+	if err := state.save(); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ saving state: %v\n", err)
+		return exitError
+	}
+
+	fmt.Printf("↩️  Reopened %s (was %s)\n", entry.Title, entry.Action)
+	if entry.Action != "snoozed" {
+		fmt.Println("GitHub can't mark threads unread through the API; you're subscribed again,")
+		fmt.Printf("and you can use \"Mark as unread\" on https://github.com/notifications for %s\n", entry.URL)
+	}
+	return exitOK
+}
+
+// reopenThread resubscribes to the thread, undoing an unsubscribe.
+func reopenThread(ctx context.Context, client *github.Client, id string) error {
+	_, _, err := client.Activity.SetThreadSubscription(ctx, id, &github.Subscription{Subscribed: github.Bool(true), Ignored: github.Bool(false)})
+	if err != nil {
+		return fmt.Errorf("resubscribing: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"text/tabwriter"
+	"time"
+)
+
+// handledActions are the history actions that dealt with a notification, as
+// opposed to undoing that.
+var handledActions = []string{"read", "auto-read", "archived", "unsubscribed", "snoozed"}
+
+// report is what `report` works out from the history.
+type report struct {
+	Since         time.Time   `json:"since"`
+	Notifications int         `json:"notifications"`
+	TopRepos      []repoCount `json:"top_repos"`
+	TopReason     string      `json:"top_reason,omitempty"`
+	// AverageToRead is from the notification's last update to us marking it
+	// read or done, over the entries that know when that was.
+	AverageToRead int64 `json:"average_to_read_seconds,omitempty"`
+	AutoApproved  int   `json:"auto_approved"`
+	Manual        int   `json:"manual"`
+}
+
+type repoCount struct {
+	Repo  string `json:"repo"`
+	Count int    `json:"count"`
+}
+
+// runReport implements `report`, a digest of what the history says we did
+// over the last -period.
+func runReport(args []string) int {
+	fs := flag.NewFlagSet("report", flag.ExitOnError)
+	period := Age(7 * 24 * time.Hour)
+	fs.Var(&period, "period", "how far back to look (e.g. 7d, 30d)")
+	asJSON := fs.Bool("json", false, "print JSON instead of text")
+	fs.Parse(args)
+	if period <= 0 {
+		fmt.Fprintln(os.Stderr, "❌ -period must be positive")
+		return exitError
+	}
+
+	state, err := loadState()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ loading state: %v\n", err)
+		return exitError
+	}
+	r := buildReport(state.History, time.Now().Add(-time.Duration(period)))
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(r); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			return exitError
+		}
+		return exitOK
+	}
+	r.print(&period)
+	return exitOK
+}
+
+// maxReportRepos is how many repos the report lists.
+const maxReportRepos = 5
+
+func buildReport(history []HistoryEntry, since time.Time) report {
+	r := report{Since: since}
+	// Counts go by notification, using the latest entry for each, so a
+	// thread read twice in the period counts once.
+	latest := map[string]HistoryEntry{}
+	var toRead time.Duration
+	timed := 0
+	for _, e := range history {
+		if e.At.Before(since) || !slices.Contains(handledActions, e.Action) {
+			continue
+		}
+		latest[e.ID] = e
+		if e.Action != "snoozed" && e.Action != "unsubscribed" && !e.UpdatedAt.IsZero() && e.At.After(e.UpdatedAt) {
+			toRead += e.At.Sub(e.UpdatedAt)
+			timed++
+		}
+	}
+	if timed > 0 {
+		r.AverageToRead = int64((toRead / time.Duration(timed)).Seconds())
+	}
+
+	var summaries []NotificationSummary
+	for _, e := range latest {
+		summaries = append(summaries, NotificationSummary{Repo: e.Repo, Reason: e.Reason})
+		if e.Action == "auto-read" {
+			r.AutoApproved++
+		} else {
+			r.Manual++
+		}
+	}
+	r.Notifications = len(latest)
+	r.TopRepos = []repoCount{}
+	repos := countBy(summaries, func(s NotificationSummary) string { return s.Repo })
+	for _, c := range repos[:min(len(repos), maxReportRepos)] {
+		r.TopRepos = append(r.TopRepos, repoCount{c.key, c.count})
+	}
+	withReason := slices.DeleteFunc(summaries, func(s NotificationSummary) bool { return s.Reason == "" })
+	if reasons := countBy(withReason, func(s NotificationSummary) string { return s.Reason }); len(reasons) > 0 {
+		r.TopReason = reasons[0].key
+	}
+	return r
+}
+
+func (r report) print(period *Age) {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "📊 Last %s (since %s)\n\n", period, r.Since.Format("Jan 2 15:04"))
+	fmt.Fprintf(tw, "Notifications handled\t%d\n", r.Notifications)
+	fmt.Fprintf(tw, "Auto-approved\t%d\n", r.AutoApproved)
+	fmt.Fprintf(tw, "Reviewed by hand\t%d\n", r.Manual)
+	if r.TopReason != "" {
+		fmt.Fprintf(tw, "Most common reason\t%s\n", r.TopReason)
+	}
+	if r.AverageToRead > 0 {
+		fmt.Fprintf(tw, "Average time to read\t%s\n", shortDuration(time.Duration(r.AverageToRead)*time.Second))
+	}
+	if len(r.TopRepos) > 0 {
+		fmt.Fprintf(tw, "\nRepo\tCount\n")
+		for _, c := range r.TopRepos {
+			fmt.Fprintf(tw, "%s\t%d\n", c.Repo, c.Count)
+		}
+	}
+	tw.Flush()
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v66/github"
+)
+
+// resolved is the web URL -resolve-urls found for each API URL. uiURL is
+// called from all over, including the serve handlers, so it's shared.
+var resolved = struct {
+	sync.Mutex
+	urls map[string]string
+}{urls: map[string]string{}}
+
+func resolvedURL(apiURL string) (string, bool) {
+	resolved.Lock()
+	defer resolved.Unlock()
+	url, ok := resolved.urls[apiURL]
+	return url, ok
+}
+
+// unguessable reports whether n has an API URL that guessURL can't map.
+func unguessable(n *github.Notification) bool {
+	url := n.GetSubject().GetURL()
+	if url == "" {
+		return false
+	}
+	_, ok := guessURL(url)
+	return !ok
+}
+
+// resolveURLs fetches the subject of each notification whose web URL can't
+// be guessed and remembers its html_url for uiURL. Results go in the
+// enrichment cache; failures are logged and leave uiURL guessing.
+func (e *enricher) resolveURLs(ctx context.Context, notifications []*github.Notification) {
+	forEachWhere(ctx, notifications, unguessable, func(n *github.Notification) error {
+		url := n.GetSubject().GetURL()
+		if d := e.cached(n); d != nil && d.HTMLURL != "" {
+			rememberURL(url, d.HTMLURL)
+			return nil
+		}
+		req, err := e.client.NewRequest("GET", url, nil)
+		if err != nil {
+			return err
+		}
+		var raw struct {
+			HTMLURL string `json:"html_url"`
+		}
+		if _, err := e.client.Do(ctx, req, &raw); err != nil {
+			return err
+		}
+		if raw.HTMLURL == "" {
+			return errors.New("no html_url in the response")
+		}
+		e.mu.Lock()
+		e.cache[url] = &cacheEntry{UpdatedAt: n.GetUpdatedAt().Time, FetchedAt: time.Now(), Details: &subjectDetails{HTMLURL: raw.HTMLURL}}
+		e.dirty = true
+		e.mu.Unlock()
+		rememberURL(url, raw.HTMLURL)
+		return nil
+	})
+}
+
+func rememberURL(apiURL, htmlURL string) {
+	resolved.Lock()
+	defer resolved.Unlock()
+	resolved.urls[apiURL] = htmlURL
+}
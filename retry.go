@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"time"
+)
+
+// transientNetworkError reports whether err looks like the network failing
+// (a dropped connection, a DNS blip, a timeout) rather than GitHub answering
+// with an error, which retrying wouldn't fix.
+func transientNetworkError(err error) bool {
+	var opErr *net.OpError
+	var dnsErr *net.DNSError
+	var netErr net.Error
+	return errors.Is(err, net.ErrClosed) ||
+		errors.As(err, &opErr) ||
+		errors.As(err, &dnsErr) ||
+		(errors.As(err, &netErr) && netErr.Timeout())
+}
+
+// withRetry runs fn, retrying transient network errors up to retries times
+// with exponential backoff from a second. what names the request in logs.
+func withRetry(ctx context.Context, retries int, what string, fn func() error) error {
+	backoff := time.Second
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt > retries || ctx.Err() != nil || !transientNetworkError(err) {
+			return err
+		}
+		log.Printf("⚠️  Network error %s (attempt %d of %d): %v; retrying in %s\n", what, attempt, retries+1, err, backoff)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/go-github/v66/github"
+)
+
+// failedMark is a notification we couldn't mark read, and why.
+type failedMark struct {
+	n *github.Notification
+	// action is what to record in the history if a retry works: "read",
+	// "auto-read", or empty for the mark-read after an unsubscribe.
+	action string
+	err    error
+}
+
+// markFailed counts a failed mark and queues it for retryFailed. A thread
+// that fails again just has its error updated.
+func (t *triager) markFailed(n *github.Notification, action string, err error) {
+	if i := slices.IndexFunc(t.failedMarks, func(f failedMark) bool { return f.n.GetID() == n.GetID() }); i >= 0 {
+		t.failedMarks[i].err = err
+		return
+	}
+	t.tally.failed++
+	t.failedMarks = append(t.failedMarks, failedMark{n, action, err})
+}
+
+// retryFailed offers to retry every failed mark once, then lists whatever
+// still failed with its error so it can be dealt with by hand.
+func (t *triager) retryFailed(ctx context.Context, prompt *prompter) {
+	if len(t.failedMarks) == 0 || ctx.Err() != nil {
+		return
+	}
+	text, err := prompt.ask(ctx, fmt.Sprintf("⚠️  %d notification(s) couldn't be marked read. Retry them? [y/N]: ", len(t.failedMarks)))
+	if err != nil {
+		return
+	}
+	if text := strings.ToLower(text); text == "y" || text == "yes" {
+		var still []failedMark
+		for _, f := range t.failedMarks {
+			if ctx.Err() != nil {
+				still = append(still, f)
+				continue
+			}
+			if err := t.apiMarkRead(ctx, f.n); err != nil {
+				f.err = err
+				still = append(still, f)
+				continue
+			}
+			t.tally.failed--
+			switch f.action {
+			case "auto-read":
+				t.tally.autoRead++
+			case "read":
+				t.tally.read++
+			}
+			if f.action != "" {
+				t.state.record(f.n, f.action)
+			}
+		}
+		t.say(fmt.Sprintf("🔁 Retried: %d of %d marked read.", len(t.failedMarks)-len(still), len(t.failedMarks)))
+		t.failedMarks = still
+	}
+	for _, f := range t.failedMarks {
+		fmt.Printf("❌ %s (%s, %s): %v\n", f.n.GetSubject().GetTitle(), f.n.GetRepository().GetFullName(), f.n.GetID(), f.err)
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lukemassa/github-notification-manager/testutil"
+)
+
+func TestRetryFailedAfterUnsubscribe(t *testing.T) {
+	server := testutil.NewMockGitHubServer(t)
+	n := testutil.NewNotification("1", "lukemassa/example", "Add a -since flag", time.Now())
+	server.AddNotification(n)
+	server.FailMarkRead("1", http.StatusBadGateway)
+
+	ctx := context.Background()
+	tr := &triager{client: server.Client(), state: &State{}}
+	if !tr.unsubscribe(ctx, n) {
+		t.Fatal("unsubscribe failed")
+	}
+	if !server.Ignored("1") || server.MarkReadCalled("1") {
+		t.Fatalf("ignored = %v, marked read = %v; want true and false", server.Ignored("1"), server.MarkReadCalled("1"))
+	}
+	if len(tr.failedMarks) != 1 || tr.tally.failed != 1 {
+		t.Fatalf("queued %d, failed %d; want the mark-read queued", len(tr.failedMarks), tr.tally.failed)
+	}
+
+	server.FailMarkRead("1", 0)
+	tr.retryFailed(ctx, newPrompter(strings.NewReader("y\n")))
+	if !server.MarkReadCalled("1") {
+		t.Error("not marked read on retry")
+	}
+	if len(tr.failedMarks) != 0 || tr.tally.failed != 0 {
+		t.Errorf("still queued %d, failed %d; want none", len(tr.failedMarks), tr.tally.failed)
+	}
+	// The retry finishes the unsubscribe rather than counting as a read.
+	if tr.tally.unsubscribed != 1 || tr.tally.read != 0 {
+		t.Errorf("unsubscribed %d, read %d; want 1 and 0", tr.tally.unsubscribed, tr.tally.read)
+	}
+	if len(tr.state.History) != 1 || tr.state.History[0].Action != "unsubscribed" {
+		t.Errorf("history %+v, want just the unsubscribe", tr.state.History)
+	}
+}
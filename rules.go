@@ -0,0 +1,232 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/v66/github"
+)
+
+// Rule matches notifications on a single field and says what to do with them.
+// Exactly one of Prefix, Suffix, Regex or Equals should be set; Value is
+// another name for Equals, which reads better for pr_merged.
+type Rule struct {
+	Name     string   `json:"name" yaml:"name"`
+	Field    string   `json:"field" yaml:"field"`
+	Prefix   string   `json:"prefix,omitempty" yaml:"prefix,omitempty"`
+	Suffix   string   `json:"suffix,omitempty" yaml:"suffix,omitempty"`
+	Regex    string   `json:"regex,omitempty" yaml:"regex,omitempty"`
+	Equals   string   `json:"equals,omitempty" yaml:"equals,omitempty"`
+	Value    string   `json:"value,omitempty" yaml:"value,omitempty"`
+	Action   string   `json:"action,omitempty" yaml:"action,omitempty"`
+	Profiles []string `json:"profiles,omitempty" yaml:"profiles,omitempty"`
+
+	re *regexp.Regexp
+}
+
+// ruleFields are the notification fields a rule can match against.
+var ruleFields = []string{"title", "repo", "type", "reason"}
+
+// detailFields are fields a rule can match that need something looked up.
+// pr_merged is "true" or "false" for pull requests and empty otherwise.
+// own_draft_ci is "true" or "false" for check suites, depending on whether
+// their branch has an open draft PR of yours, and empty otherwise or if the
+// branch couldn't be worked out.
+var detailFields = []string{"pr_merged", "own_draft_ci"}
+
+// ruleActions are the things a rule can do to a matching notification.
+var ruleActions = []string{"mark-read", "skip"}
+
+// builtinRules ship with the tool. renovate opens conventional-commit
+// dependency bumps; dependabot opens "Bump x from 1.0 to 1.1", optionally
+// with a conventional-commit prefix of its own. state-change covers threads
+// that only got a notification because they were merged or closed,
+// ci-activity covers workflow runs you triggered, merged covers pull
+// requests that have been merged, and draft-ci covers CI on your own draft
+// pull requests.
+var builtinRules = map[string][]Rule{
+	"renovate": {
+		{Name: "renovate", Field: "title", Prefix: "chore(deps)", Action: "mark-read"},
+		{Name: "renovate", Field: "title", Prefix: "fix(deps)", Action: "mark-read"},
+	},
+	"dependabot": {
+		{Name: "dependabot", Field: "title", Regex: `^(\w+(\([\w-]+\))?: )?[Bb]ump \S+ from \S+ to \S+`, Action: "mark-read"},
+	},
+	"state-change": {
+		{Name: "state-change", Field: "reason", Equals: "state_change", Action: "mark-read"},
+	},
+	"ci-activity": {
+		{Name: "ci-activity", Field: "reason", Equals: "ci_activity", Action: "mark-read"},
+	},
+	"merged": {
+		{Name: "merged", Field: "pr_merged", Equals: "true", Action: "mark-read"},
+	},
+	"draft-ci": {
+		{Name: "draft-ci", Field: "own_draft_ci", Equals: "true", Action: "mark-read"},
+	},
+}
+
+// builtin returns fresh, compiled copies of the named built-in rules.
+func builtin(name string) []*Rule {
+	var rules []*Rule
+	for _, r := range builtinRules[name] {
+		if err := r.compile(); err != nil {
+			panic(err)
+		}
+		rules = append(rules, &r)
+	}
+	return rules
+}
+
+// defaultRules are on unless the config turns them off.
+func defaultRules() []*Rule {
+	return builtin("renovate")
+}
+
+// depRules are what -auto-deps turns on.
+func depRules() []*Rule {
+	return append(builtin("renovate"), builtin("dependabot")...)
+}
+
+// compile checks the rule is well formed and prepares it for matching.
+func (r *Rule) compile() error {
+	if r.Name == "" {
+		return fmt.Errorf("rule is missing a name")
+	}
+	if !slices.Contains(ruleFields, r.Field) && !slices.Contains(detailFields, r.Field) {
+		return fmt.Errorf("rule %q: unknown field %q (want one of %s)", r.Name, r.Field, strings.Join(append(slices.Clone(ruleFields), detailFields...), ", "))
+	}
+	if r.Value != "" {
+		if r.Equals != "" {
+			return fmt.Errorf("rule %q: value and equals are the same thing; set one", r.Name)
+		}
+		r.Equals, r.Value = r.Value, ""
+	}
+	if r.Action == "" {
+		r.Action = "mark-read"
+	}
+	if !slices.Contains(ruleActions, r.Action) {
+		return fmt.Errorf("rule %q: unknown action %q (want one of %s)", r.Name, r.Action, strings.Join(ruleActions, ", "))
+	}
+	set := 0
+	for _, m := range []string{r.Prefix, r.Suffix, r.Regex, r.Equals} {
+		if m != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return fmt.Errorf("rule %q: exactly one of prefix, suffix, regex or equals must be set", r.Name)
+	}
+	if r.Field == "repo" && r.Equals != "" && !validRepo(r.Equals) {
+		return fmt.Errorf("rule %q: %q is not of the form owner/name", r.Name, r.Equals)
+	}
+	if r.Regex != "" {
+		re, err := regexp.Compile(r.Regex)
+		if err != nil {
+			return fmt.Errorf("rule %q: %w", r.Name, err)
+		}
+		r.re = re
+	}
+	return nil
+}
+
+// needsDetails reports whether the rule matches on a field that has to be
+// looked up.
+func (r *Rule) needsDetails() bool {
+	return slices.Contains(detailFields, r.Field)
+}
+
+// needsSubject reports whether the rule matches on the subject's details;
+// own_draft_ci is looked up separately, by ownDraftCI.
+func (r *Rule) needsSubject() bool {
+	return r.needsDetails() && r.Field != "own_draft_ci"
+}
+
+// value is the field of n the rule looks at. d is n's subject details, if
+// they've been looked up.
+func (r *Rule) value(n *github.Notification, d *subjectDetails) string {
+	switch r.Field {
+	case "pr_merged":
+		if d == nil || n.GetSubject().GetType() != "PullRequest" {
+			return ""
+		}
+		return strconv.FormatBool(d.Merged)
+	case "own_draft_ci":
+		if d == nil || n.GetSubject().GetType() != "CheckSuite" {
+			return ""
+		}
+		return strconv.FormatBool(d.OwnDraftCI)
+	case "title":
+		return n.GetSubject().GetTitle()
+	case "repo":
+		return n.GetRepository().GetFullName()
+	case "type":
+		return n.GetSubject().GetType()
+	case "reason":
+		return n.GetReason()
+	}
+	return ""
+}
+
+// describe says what the rule looks for, e.g. `has prefix "chore(deps)"`.
+func (r *Rule) describe() string {
+	switch {
+	case r.Prefix != "":
+		return fmt.Sprintf("has prefix %q", r.Prefix)
+	case r.Suffix != "":
+		return fmt.Sprintf("has suffix %q", r.Suffix)
+	case r.Regex != "":
+		return fmt.Sprintf("matches /%s/", r.Regex)
+	default:
+		return fmt.Sprintf("equals %q", r.Equals)
+	}
+}
+
+func (r *Rule) matches(n *github.Notification, d *subjectDetails) bool {
+	value := r.value(n, d)
+	switch {
+	case r.Prefix != "":
+		return strings.HasPrefix(value, r.Prefix)
+	case r.Suffix != "":
+		return strings.HasSuffix(value, r.Suffix)
+	case r.re != nil:
+		return r.re.MatchString(value)
+	default:
+		return value == r.Equals
+	}
+}
+
+// appliesTo reports whether the rule is active for the given profile. Rules
+// without a profile list apply everywhere.
+func (r *Rule) appliesTo(profile string) bool {
+	return len(r.Profiles) == 0 || slices.Contains(r.Profiles, profile)
+}
+
+// matchRule returns the first rule that matches n, or nil.
+func matchRule(rules []*Rule, n *github.Notification, d *subjectDetails) *Rule {
+	for _, r := range rules {
+		if r.matches(n, d) {
+			return r
+		}
+	}
+	return nil
+}
+
+// addRules appends the extra rules to rules, skipping any with the same name
+// and pattern as one already there.
+func addRules(rules, extra []*Rule) []*Rule {
+	for _, e := range extra {
+		if !slices.ContainsFunc(rules, func(r *Rule) bool { return r.same(e) }) {
+			rules = append(rules, e)
+		}
+	}
+	return rules
+}
+
+func (r *Rule) same(o *Rule) bool {
+	return r.Name == o.Name && r.Field == o.Field && r.Prefix == o.Prefix &&
+		r.Suffix == o.Suffix && r.Regex == o.Regex && r.Equals == o.Equals
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v66/github"
+	"github.com/lukemassa/github-notification-manager/testutil"
+)
+
+func TestRenovateRules(t *testing.T) {
+	tests := []struct {
+		title string
+		want  bool
+	}{
+		{"chore(deps): update module golang.org/x/term to v0.37.0", true},
+		{"fix(deps): update module github.com/google/go-github to v66", true},
+		{"Chore(deps): update actions/checkout action to v5", false},
+		{"FIX(DEPS): update dependency yaml to v3", false},
+		{"chore: tidy go.mod", false},
+		{"Add a -since flag", false},
+		{"Update chore(deps) docs", false},
+	}
+	rules := builtin("renovate")
+	for _, tt := range tests {
+		n := testutil.NewNotification("1", "lukemassa/example", tt.title, time.Now())
+		rule := matchRule(rules, n, nil)
+		if got := rule != nil; got != tt.want {
+			t.Errorf("matchRule(renovate, %q) matched = %v, want %v", tt.title, got, tt.want)
+		}
+		if rule != nil && rule.Action != "mark-read" {
+			t.Errorf("matchRule(renovate, %q).Action = %q, want mark-read", tt.title, rule.Action)
+		}
+	}
+}
+
+func TestDependabotRule(t *testing.T) {
+	tests := []struct {
+		title string
+		want  bool
+	}{
+		{"Bump foo from 1.0 to 1.1", true},
+		{"bump golang.org/x/net from 0.30.0 to 0.31.0", true},
+		{"build(deps): bump foo from 1 to 2", true},
+		{"chore(deps-dev): Bump eslint from 9.1.0 to 9.2.0 in /web", true},
+		{"Bump version to 2.0", false},
+		{"Bumped foo from 1 to 2", false},
+		{"Bump foo", false},
+		{"Revert \"Bump foo from 1.0 to 1.1\"", false},
+		{"chore(deps): update foo to v2", false},
+	}
+	rules := builtin("dependabot")
+	for _, tt := range tests {
+		n := testutil.NewNotification("1", "lukemassa/example", tt.title, time.Now())
+		if got := matchRule(rules, n, nil) != nil; got != tt.want {
+			t.Errorf("matchRule(dependabot, %q) matched = %v, want %v", tt.title, got, tt.want)
+		}
+	}
+
+	// -auto-deps turns on both bots' rules.
+	for _, title := range []string{"chore(deps): update foo to v2", "Bump foo from 1.0 to 1.1"} {
+		n := testutil.NewNotification("1", "lukemassa/example", title, time.Now())
+		if matchRule(depRules(), n, nil) == nil {
+			t.Errorf("matchRule(depRules, %q) = nil, want a match", title)
+		}
+	}
+}
+
+func TestConfiguredPrefixRule(t *testing.T) {
+	r := &Rule{Name: "bumps", Field: "title", Prefix: "build(deps)"}
+	if err := r.compile(); err != nil {
+		t.Fatal(err)
+	}
+	rules := append(builtin("renovate"), r)
+	for title, want := range map[string]*Rule{
+		"build(deps): bump golang.org/x/sys": r,
+		"chore(deps): bump golang.org/x/sys": rules[0],
+		"Build(deps): bump golang.org/x/sys": nil,
+	} {
+		n := testutil.NewNotification("1", "lukemassa/example", title, time.Now())
+		if got := matchRule(rules, n, nil); got != want {
+			t.Errorf("matchRule(%q) = %v, want %v", title, got, want)
+		}
+	}
+}
+
+func TestRenovateAutoRead(t *testing.T) {
+	server := testutil.NewMockGitHubServer(t)
+	notifications := []struct {
+		id, title string
+		marked    bool
+	}{
+		{"1", "chore(deps): update golang.org/x/term", true},
+		{"2", "fix(deps): update gopkg.in/yaml.v3", true},
+		{"3", "Chore(deps): update actions/setup-go", false},
+		{"4", "Add a -since flag", false},
+	}
+	settings := &Settings{Rules: defaultRules()}
+	tr := &triager{client: server.Client(), state: &State{}}
+	for _, tt := range notifications {
+		n := testutil.NewNotification(tt.id, "lukemassa/example", tt.title, time.Now())
+		server.AddNotification(n)
+		if settings.decide(n, time.Now()).Action == "mark-read" {
+			tr.autoReadQuietly(context.Background(), n)
+		}
+	}
+	for _, tt := range notifications {
+		if got := server.MarkReadCalled(tt.id); got != tt.marked {
+			t.Errorf("%q marked read = %v, want %v", tt.title, got, tt.marked)
+		}
+	}
+	if tr.tally.autoRead != 2 || tr.tally.failed != 0 {
+		t.Errorf("auto-read %d, failed %d; want 2 and 0", tr.tally.autoRead, tr.tally.failed)
+	}
+}
+
+func BenchmarkRuleEngine(b *testing.B) {
+	var rules []*Rule
+	for i := range 100 {
+		r := &Rule{Name: fmt.Sprint("rule", i), Field: "title"}
+		switch i % 3 {
+		case 0:
+			r.Prefix = fmt.Sprintf("chore(deps-%d)", i)
+		case 1:
+			r.Suffix = fmt.Sprintf("[skip %d]", i)
+		case 2:
+			r.Regex = fmt.Sprintf(`^release v\d+\.%d\.\d+$`, i)
+		}
+		if err := r.compile(); err != nil {
+			b.Fatal(err)
+		}
+		rules = append(rules, r)
+	}
+	notifications := make([]*github.Notification, 1000)
+	for i := range notifications {
+		notifications[i] = testutil.NewNotification(fmt.Sprint(i), "lukemassa/example", fmt.Sprintf("Fix issue #%d in the fetch loop", i), time.Now())
+	}
+	b.ReportAllocs()
+	for b.Loop() {
+		for _, n := range notifications {
+			matchRule(rules, n, nil)
+		}
+	}
+}
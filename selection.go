@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v66/github"
+)
+
+// parseSelection parses indices and ranges like "1-5,8,12" (1-based) into
+// sorted, de-duplicated 0-based indices below n. "all" selects everything.
+// Reversed ranges ("5-1") are fine; anything out of range is an error.
+func parseSelection(s string, n int) ([]int, error) {
+	if strings.EqualFold(strings.TrimSpace(s), "all") {
+		all := make([]int, n)
+		for i := range all {
+			all[i] = i
+		}
+		return all, nil
+	}
+	seen := map[int]bool{}
+	for _, part := range splitList(s) {
+		from, to, isRange := strings.Cut(part, "-")
+		lo, err := strconv.Atoi(strings.TrimSpace(from))
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number or range", part)
+		}
+		hi := lo
+		if isRange {
+			if hi, err = strconv.Atoi(strings.TrimSpace(to)); err != nil {
+				return nil, fmt.Errorf("%q is not a number or range", part)
+			}
+		}
+		if lo > hi {
+			lo, hi = hi, lo
+		}
+		if lo < 1 || hi > n {
+			return nil, fmt.Errorf("%q is out of range (1-%d)", part, n)
+		}
+		for i := lo; i <= hi; i++ {
+			seen[i-1] = true
+		}
+	}
+	var picked []int
+	for i := range seen {
+		picked = append(picked, i)
+	}
+	slices.Sort(picked)
+	return picked, nil
+}
+
+// selectTriage lists the notifications numbered, in the order given, and
+// marks the ones the user picks read in one batch.
+func selectTriage(ctx context.Context, prompt *prompter, t *triager, notifications []*github.Notification) {
+	now := time.Now()
+	for i, n := range notifications {
+		s := summarize(n)
+		ref := s.Repo
+		if num := subjectNumber(s.URL); num != "" {
+			ref += "#" + num
+		}
+		fmt.Printf("%3d. %s %s %s (%s)\n", i+1, subjectIcon(s.Type), ref, s.Title, formatTime(s.UpdatedAt, now, "short-relative"))
+	}
+
+	var picked []int
+	for {
+		text, err := prompt.ask(ctx, "Mark which read? [e.g. 1-5,8,12 or all; Enter for none]: ")
+		if err != nil || text == "" {
+			fmt.Println("👍 Left everything alone.")
+			return
+		}
+		if picked, err = parseSelection(text, len(notifications)); err == nil {
+			break
+		}
+		fmt.Printf("⚠️  %v\n", err)
+	}
+	if len(picked) == 0 {
+		fmt.Println("👍 Left everything alone.")
+		return
+	}
+
+	text, err := prompt.ask(ctx, fmt.Sprintf("Mark %d notification(s) read? [y/N]: ", len(picked)))
+	if err != nil {
+		return
+	}
+	if text := strings.ToLower(text); text != "y" && text != "yes" {
+		fmt.Println("👍 Left everything alone.")
+		return
+	}
+	var chosen []*github.Notification
+	for _, i := range picked {
+		chosen = append(chosen, notifications[i])
+	}
+	t.readAll(ctx, chosen)
+	t.say(fmt.Sprintf("✅ Marked %d read.", t.tally.read))
+}
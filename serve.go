@@ -0,0 +1,308 @@
+package main
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v66/github"
+)
+
+// webUI is the single-page app served at /. It only uses the JSON API.
+//
+//go:embed web
+var webUI embed.FS
+
+// runServe implements `serve`, which polls GitHub in the background and
+// serves the result as a small local JSON API for other tools to consume.
+func runServe(args []string) int {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	var opts Options
+	opts.register(fs)
+	port := fs.Int("port", 8080, "port to listen on (localhost only)")
+	interval := fs.Duration("interval", time.Minute, "how often to poll GitHub")
+	allowOrigin := fs.String("allow-origin", "", "comma-separated origins besides the server's own that may call the API from a browser (e.g. chrome-extension://<id>)")
+	fs.Parse(args)
+
+	if *interval <= 0 {
+		fmt.Fprintln(os.Stderr, "❌ -interval must be positive")
+		return exitError
+	}
+	settings, err := opts.resolve()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitStatus(err)
+	}
+	if settings.Token == "" {
+		fmt.Fprintln(os.Stderr, "❌ GITHUB_TOKEN environment variable (or a token in the config) is required")
+		return exitAuth
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	client, _, err := newClient(ctx, settings)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitStatus(err)
+	}
+
+	s := &server{client: client, settings: settings, enricher: newEnricher(client), port: strconv.Itoa(*port), allowOrigins: splitList(*allowOrigin)}
+	// Only ever on localhost: anyone who can reach it can mark things read
+	// with your token.
+	srv := &http.Server{Addr: net.JoinHostPort("127.0.0.1", s.port), Handler: s.routes()}
+	go s.pollEvery(ctx, *interval)
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdown)
+	}()
+
+	fmt.Printf("🛰️  Serving notifications on http://%s (Ctrl-C to stop)\n", srv.Addr)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitError
+	}
+	return exitOK
+}
+
+// server holds the most recent poll for the HTTP handlers.
+type server struct {
+	client   *github.Client
+	settings *Settings
+	enricher *enricher
+	// port is what the server listens on, and so the only one requests may
+	// be addressed to.
+	port string
+	// allowOrigins are browser origins, besides our own, that may call the
+	// API.
+	allowOrigins []string
+
+	// byRepo is the last listing per repo; only the polling goroutine
+	// touches it.
+	byRepo map[string]*listing
+
+	// mu guards everything below; poll publishes a new notifications
+	// slice under it and the handlers read and change it under it.
+	mu            sync.Mutex
+	notifications []*github.Notification // newest first
+	fetchedAt     time.Time
+	lastErr       error
+	// readAt is when we marked threads read, so a repo that answers the
+	// next poll with 304 doesn't bring them back.
+	readAt map[string]time.Time
+}
+
+func (s *server) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /notifications", s.handleList)
+	mux.HandleFunc("GET /notifications/{id}", s.handleGet)
+	mux.HandleFunc("POST /notifications/{id}/read", s.handleRead)
+	mux.HandleFunc("GET /stats", s.handleStats)
+	web, _ := fs.Sub(webUI, "web") // can't fail: the directory is embedded
+	mux.Handle("GET /", http.FileServerFS(web))
+	return s.localOnly(mux)
+}
+
+// localOnly refuses requests a web page could make on the user's behalf.
+// Listening on 127.0.0.1 isn't enough: any page can POST to it blind, and
+// with DNS rebinding read from it too. A request must be addressed to
+// localhost or 127.0.0.1 on our port, and one from a browser must come from
+// the embedded UI or an -allow-origin.
+func (s *server) localOnly(next http.Handler) http.Handler {
+	hosts := []string{net.JoinHostPort("127.0.0.1", s.port), net.JoinHostPort("localhost", s.port)}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := strings.ToLower(r.Host)
+		if !slices.Contains(hosts, host) {
+			writeJSON(w, http.StatusForbidden, map[string]string{"error": "unexpected Host " + r.Host})
+			return
+		}
+		if origin := r.Header.Get("Origin"); origin != "" && origin != "http://"+host && !slices.Contains(s.allowOrigins, origin) {
+			writeJSON(w, http.StatusForbidden, map[string]string{"error": "origin " + origin + " isn't allowed; see -allow-origin"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *server) pollEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		s.poll(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll refreshes the cached notifications. Requests are conditional, as in
+// -watch, so an unchanged inbox doesn't cost rate limit; that's done per repo
+// so a repo that hasn't changed keeps what we had for it.
+func (s *server) poll(ctx context.Context) {
+	if s.byRepo == nil {
+		s.byRepo = map[string]*listing{}
+	}
+	fo := s.settings.fetchOptions()
+	var err error
+	for _, repo := range s.settings.Repos {
+		owner, name, _ := strings.Cut(repo, "/")
+		var prev *listing
+		if fo.listingKey(repo) != "" {
+			prev = s.byRepo[repo]
+		}
+		l, _, rerr := fetchRepoUnread(ctx, s.client, owner, name, fo, prev)
+		if errors.Is(rerr, errNotModified) {
+			continue
+		}
+		if rerr != nil {
+			if ctx.Err() == nil {
+				log.Printf("⚠️  Failed to fetch notifications for %s: %v\n", repo, rerr)
+			}
+			err = rerr
+			continue
+		}
+		s.byRepo[repo] = l
+	}
+
+	var notifications []*github.Notification
+	for _, repo := range s.settings.Repos {
+		if l := s.byRepo[repo]; l != nil {
+			notifications = append(notifications, l.Notifications...)
+		}
+	}
+	notifications = applyFilters(dedupeByID(notifications), s.settings)
+	notifications = applyEnrichedFilters(ctx, s.enricher, notifications, s.settings)
+	if err := s.enricher.save(); err != nil {
+		log.Printf("⚠️  Failed to save enrichment cache: %v\n", err)
+	}
+	sortNewestFirst(notifications)
+
+	// Leaving out what's been read has to happen under the same lock as
+	// the swap: handleRead only drops a thread from the list it sees, so
+	// one read while we were fetching would otherwise come back.
+	s.mu.Lock()
+	s.notifications = filterNotifications(notifications, func(n *github.Notification) bool {
+		at, read := s.readAt[n.GetID()]
+		return !read || n.GetUpdatedAt().Time.After(at)
+	})
+	s.fetchedAt, s.lastErr = time.Now(), err
+	s.mu.Unlock()
+}
+
+// find returns the cached notification with the given thread ID.
+func (s *server) find(id string) *github.Notification {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := slices.IndexFunc(s.notifications, func(n *github.Notification) bool { return n.GetID() == id })
+	if i < 0 {
+		return nil
+	}
+	return s.notifications[i]
+}
+
+func (s *server) handleList(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	list := summarizeAll(s.notifications)
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (s *server) handleGet(w http.ResponseWriter, r *http.Request) {
+	n := s.find(r.PathValue("id"))
+	if n == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "no such unread notification"})
+		return
+	}
+	writeJSON(w, http.StatusOK, summarize(n))
+}
+
+// handleRead marks the thread read on GitHub, drops it from the cache and
+// records it in the history like the interactive session does.
+func (s *server) handleRead(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	n := s.find(id)
+	if n == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "no such unread notification"})
+		return
+	}
+	if !inSafeRepos(s.settings.SafeRepos, n) {
+		writeJSON(w, http.StatusForbidden, map[string]string{"error": "not in safe_repos"})
+		return
+	}
+	if _, err := s.client.Activity.MarkThreadRead(r.Context(), id); err != nil {
+		writeJSON(w, http.StatusBadGateway, map[string]string{"error": err.Error()})
+		return
+	}
+
+	s.mu.Lock()
+	s.notifications = slices.DeleteFunc(s.notifications, func(n *github.Notification) bool { return n.GetID() == id })
+	if s.readAt == nil {
+		s.readAt = map[string]time.Time{}
+	}
+	s.readAt[id] = time.Now()
+	state, err := loadState()
+	if err == nil {
+		state.record(n, "read")
+		err = state.save()
+	}
+	s.mu.Unlock()
+	if err != nil {
+		log.Printf("⚠️  Failed to record %s in the history: %v\n", id, err)
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"id": id, "status": "read"})
+}
+
+func (s *server) handleStats(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	summaries := summarizeAll(s.notifications)
+	stats := struct {
+		Total     int            `json:"total"`
+		Repo      map[string]int `json:"repo"`
+		Type      map[string]int `json:"type"`
+		Reason    map[string]int `json:"reason"`
+		FetchedAt time.Time      `json:"fetched_at"`
+		Error     string         `json:"error,omitempty"`
+	}{Total: len(summaries), FetchedAt: s.fetchedAt}
+	if s.lastErr != nil {
+		stats.Error = s.lastErr.Error()
+	}
+	s.mu.Unlock()
+
+	tally := func(key func(NotificationSummary) string) map[string]int {
+		counts := map[string]int{}
+		for _, c := range countBy(summaries, key) {
+			counts[c.key] = c.count
+		}
+		return counts
+	}
+	stats.Repo = tally(func(s NotificationSummary) string { return s.Repo })
+	stats.Type = tally(func(s NotificationSummary) string { return s.Type })
+	stats.Reason = tally(func(s NotificationSummary) string { return s.Reason })
+	writeJSON(w, http.StatusOK, stats)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		log.Printf("⚠️  Failed to write response: %v\n", err)
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v66/github"
+	"github.com/lukemassa/github-notification-manager/testutil"
+)
+
+func TestServeLocalOnly(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	tests := []struct {
+		name, method, target, host, origin string
+		want                               int
+		marked                             bool
+	}{
+		{"list", "GET", "/notifications", "127.0.0.1:8080", "", http.StatusOK, false},
+		{"list via localhost", "GET", "/notifications", "localhost:8080", "", http.StatusOK, false},
+		{"list via rebound name", "GET", "/notifications", "evil.example:8080", "", http.StatusForbidden, false},
+		{"list on another port", "GET", "/notifications", "127.0.0.1:9090", "", http.StatusForbidden, false},
+		{"read from another site", "POST", "/notifications/1/read", "127.0.0.1:8080", "https://evil.example", http.StatusForbidden, false},
+		{"read from the page on another port", "POST", "/notifications/1/read", "127.0.0.1:8080", "http://127.0.0.1:9090", http.StatusForbidden, false},
+		{"read via rebound name", "POST", "/notifications/1/read", "evil.example:8080", "http://evil.example:8080", http.StatusForbidden, false},
+		{"read from an allowed extension", "POST", "/notifications/1/read", "127.0.0.1:8080", "chrome-extension://abc", http.StatusOK, true},
+		{"read from the embedded UI", "POST", "/notifications/1/read", "localhost:8080", "http://localhost:8080", http.StatusOK, true},
+		{"read without a browser", "POST", "/notifications/1/read", "127.0.0.1:8080", "", http.StatusOK, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gh := testutil.NewMockGitHubServer(t)
+			n := testutil.NewNotification("1", "lukemassa/example", "Add a -since flag", time.Now())
+			gh.AddNotification(n)
+			s := &server{client: gh.Client(), settings: &Settings{}, port: "8080", allowOrigins: []string{"chrome-extension://abc"}}
+			s.notifications = []*github.Notification{n}
+
+			req := httptest.NewRequest(tt.method, tt.target, nil)
+			req.Host = tt.host
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			rec := httptest.NewRecorder()
+			s.routes().ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status %d, want %d: %s", rec.Code, tt.want, rec.Body)
+			}
+			if got := gh.MarkReadCalled("1"); got != tt.marked {
+				t.Errorf("marked read = %v, want %v", got, tt.marked)
+			}
+		})
+	}
+}
+
+// hookTransport runs hook before passing on each request.
+type hookTransport struct {
+	base http.RoundTripper
+	hook func(*http.Request)
+}
+
+func (t *hookTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.hook(req)
+	return t.base.RoundTrip(req)
+}
+
+func TestServeReadDuringPoll(t *testing.T) {
+	isolate(t)
+	gh := testutil.NewMockGitHubServer(t)
+	gh.Login = "lukemassa"
+	gh.AddNotification(testutil.NewNotification("1", "lukemassa/example", "Add a -since flag", time.Now()))
+	gh.AddNotification(checkSuite("2", "lukemassa/example", "CI workflow run failed for my-draft branch"))
+
+	s := &server{settings: &Settings{Repos: []string{"lukemassa/example"}, Rules: builtin("draft-ci")}, port: "8080"}
+	// The draft CI lookup happens after the listing is fetched and before
+	// the poll publishes it, so reading thread 1 there is a read landing
+	// in the middle of a poll.
+	read := false
+	s.client = gh.ClientWithTransport(func(base http.RoundTripper) http.RoundTripper {
+		return &hookTransport{base, func(req *http.Request) {
+			if req.URL.Path != "/user" || read {
+				return
+			}
+			read = true
+			rec := httptest.NewRecorder()
+			req = httptest.NewRequest("POST", "/notifications/1/read", nil)
+			req.Host = "127.0.0.1:8080"
+			s.routes().ServeHTTP(rec, req)
+			if rec.Code != http.StatusOK {
+				t.Errorf("read: status %d: %s", rec.Code, rec.Body)
+			}
+		}}
+	})
+	s.enricher = &enricher{client: s.client}
+	s.notifications = []*github.Notification{testutil.NewNotification("1", "lukemassa/example", "Add a -since flag", time.Now())}
+
+	s.poll(context.Background())
+	if !read {
+		t.Fatal("the poll never looked up the user")
+	}
+	if s.find("1") != nil {
+		t.Error("thread 1 is back after being read during the poll")
+	}
+	if s.find("2") == nil {
+		t.Error("thread 2 is missing")
+	}
+}
+
+// TestServeConcurrentPolls is for -race: handlers and polls share the list.
+func TestServeConcurrentPolls(t *testing.T) {
+	isolate(t)
+	gh := testutil.NewMockGitHubServer(t)
+	addNotifications(gh, "lukemassa/example", "a", 20)
+	s := &server{client: gh.Client(), settings: &Settings{Repos: []string{"lukemassa/example"}}, port: "8080"}
+	s.enricher = &enricher{client: s.client}
+	s.poll(context.Background())
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for range 5 {
+			s.poll(context.Background())
+		}
+	}()
+	for i := 1; i <= 20; i++ {
+		for _, target := range []string{"/notifications", "/stats", fmt.Sprintf("/notifications/a%d", i)} {
+			req := httptest.NewRequest("GET", target, nil)
+			req.Host = "127.0.0.1:8080"
+			s.routes().ServeHTTP(httptest.NewRecorder(), req)
+		}
+		req := httptest.NewRequest("POST", fmt.Sprintf("/notifications/a%d/read", i), nil)
+		req.Host = "127.0.0.1:8080"
+		s.routes().ServeHTTP(httptest.NewRecorder(), req)
+	}
+	wg.Wait()
+	s.poll(context.Background())
+	if n := len(s.notifications); n != 0 {
+		t.Errorf("%d notification(s) left after reading them all, want 0", n)
+	}
+}
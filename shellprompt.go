@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// defaultPrompt is -output prompt's template: pull requests, issues and
+// mentions, leaving out any that are zero.
+const defaultPrompt = `{{with .PullRequests}}PR:{{.}} {{end}}{{with .Issues}}I:{{.}} {{end}}{{with .Mentions}}@:{{.}}{{end}}`
+
+// promptCounts is what the -output prompt template sees.
+type promptCounts struct {
+	Total        int
+	PullRequests int
+	Issues       int
+	Mentions     int
+	byType       map[string]int
+	byReason     map[string]int
+}
+
+// Type counts notifications with this subject type, e.g. {{.Type "Release"}}.
+func (c promptCounts) Type(t string) int { return c.byType[t] }
+
+// Reason counts notifications with this reason, e.g.
+// {{.Reason "review_requested"}}.
+func (c promptCounts) Reason(r string) int { return c.byReason[r] }
+
+func parsePrompt(text string) (*template.Template, error) {
+	return template.New("prompt").Option("missingkey=error").Parse(text)
+}
+
+// writePrompt prints one line of counts for a shell prompt or status bar.
+// Runs of spaces left by omitted segments are squeezed out.
+func writePrompt(w io.Writer, text string, summaries []NotificationSummary) error {
+	tmpl, err := parsePrompt(text)
+	if err != nil {
+		return err
+	}
+	c := promptCounts{Total: len(summaries), byType: map[string]int{}, byReason: map[string]int{}}
+	for _, s := range summaries {
+		c.byType[s.Type]++
+		c.byReason[s.Reason]++
+	}
+	c.PullRequests, c.Issues, c.Mentions = c.byType["PullRequest"], c.byType["Issue"], c.byReason["mention"]
+	var b strings.Builder
+	if err := tmpl.Execute(&b, c); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, strings.Join(strings.Fields(b.String()), " "))
+	return err
+}
@@ -0,0 +1,11 @@
+//go:build !unix
+
+package main
+
+import "os"
+
+// resizeSignals is empty where there's no SIGWINCH; the status line picks up
+// the new width on its next redraw instead.
+func resizeSignals() []os.Signal {
+	return nil
+}
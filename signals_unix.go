@@ -0,0 +1,13 @@
+//go:build unix
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// resizeSignals are the signals that mean the terminal changed size.
+func resizeSignals() []os.Signal {
+	return []os.Signal{syscall.SIGWINCH}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v66/github"
+)
+
+// snooze hides the thread until the given time.
+func (s *State) snooze(id string, until time.Time) {
+	if s.Snoozed == nil {
+		s.Snoozed = map[string]time.Time{}
+	}
+	s.Snoozed[id] = until
+}
+
+// filterSnoozed drops snoozed notifications, forgetting snoozes that have
+// expired. It returns the kept notifications and how many were hidden.
+func (s *State) filterSnoozed(notifications []*github.Notification, now time.Time) ([]*github.Notification, int) {
+	for id, until := range s.Snoozed {
+		if !now.Before(until) {
+			delete(s.Snoozed, id)
+		}
+	}
+	kept := filterNotifications(notifications, func(n *github.Notification) bool {
+		_, snoozed := s.Snoozed[n.GetID()]
+		return !snoozed
+	})
+	return kept, len(notifications) - len(kept)
+}
+
+// wake forgets the snoozes on ids, or on everything if ids is just "all",
+// and returns the IDs it woke, sorted. IDs that weren't snoozed are left out.
+func (s *State) wake(ids []string) []string {
+	if len(ids) == 1 && strings.EqualFold(ids[0], "all") {
+		ids = slices.Collect(maps.Keys(s.Snoozed))
+	}
+	var woken []string
+	for _, id := range ids {
+		if _, ok := s.Snoozed[id]; ok {
+			delete(s.Snoozed, id)
+			woken = append(woken, id)
+		}
+	}
+	slices.Sort(woken)
+	return woken
+}
+
+// wakeSnoozed implements -wake: it un-snoozes ids, says which it woke, and
+// returns just the woken notifications so the session goes over those.
+func wakeSnoozed(s *State, notifications []*github.Notification, ids []string) []*github.Notification {
+	woken := s.wake(ids)
+	if len(woken) == 0 {
+		statusf("😴 Nothing to wake: none of those are snoozed.\n")
+		return nil
+	}
+	statusf("⏰ Woke %d snoozed notification(s): %s\n", len(woken), strings.Join(woken, ", "))
+	kept := filterNotifications(notifications, func(n *github.Notification) bool {
+		return slices.Contains(woken, n.GetID())
+	})
+	if gone := len(woken) - len(kept); gone > 0 {
+		statusf("   %d of them aren't unread any more.\n", gone)
+	}
+	return kept
+}
@@ -0,0 +1,431 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/google/go-github/v66/github"
+)
+
+// sortExpr is a compiled -sort-expr. It's either a comparison of two
+// notifications, a and b, that says whether a sorts before b, or a key
+// computed from a single notification's fields and sorted ascending:
+//
+//	a.repo < b.repo
+//	reason == "review_requested" ? 0 : 1
+//
+// The language is deliberately small: string, number and boolean literals,
+// the fields in sortFields, comparisons, && || !, parentheses and ?:.
+type sortExpr struct {
+	eval func(a, b *github.Notification) (any, error)
+	// pair is set when the expression compares a and b rather than giving a
+	// key.
+	pair bool
+}
+
+// sortFields are the fields an expression can use, bare or as a.field and
+// b.field. updated_at is in seconds since the epoch.
+var sortFields = append(slices.Clone(ruleFields), "id", "updated_at")
+
+func fieldValue(n *github.Notification, field string) any {
+	switch field {
+	case "id":
+		return n.GetID()
+	case "updated_at":
+		return float64(n.GetUpdatedAt().Unix())
+	}
+	return (&Rule{Field: field}).value(n, nil)
+}
+
+// parseSortExpr compiles s, and tries it on an empty pair of notifications
+// so type errors show up before anything is fetched.
+func parseSortExpr(s string) (*sortExpr, error) {
+	p := &exprParser{src: s}
+	if err := p.lex(); err != nil {
+		return nil, err
+	}
+	eval, err := p.ternary()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q", p.tokens[p.pos])
+	}
+	if p.bare && p.pair {
+		return nil, errors.New("use either bare fields (a sort key) or a. and b. fields (a comparison), not both")
+	}
+	e := &sortExpr{eval: eval, pair: p.pair}
+	v, err := eval(&github.Notification{}, &github.Notification{})
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := v.(bool); e.pair && !ok {
+		return nil, errors.New("a comparison of a and b must be true or false")
+	}
+	return e, nil
+}
+
+// sort reorders notifications by the expression, stably, so anything it
+// doesn't tell apart stays in the order given.
+func (e *sortExpr) sort(notifications []*github.Notification) error {
+	var firstErr error
+	less := func(a, b *github.Notification) bool {
+		v, err := e.eval(a, b)
+		if err != nil {
+			firstErr = cmp.Or(firstErr, err)
+			return false
+		}
+		return v == true
+	}
+	slices.SortStableFunc(notifications, func(a, b *github.Notification) int {
+		if !e.pair {
+			ka, erra := e.eval(a, nil)
+			kb, errb := e.eval(b, nil)
+			if erra != nil || errb != nil {
+				firstErr = cmp.Or(firstErr, erra, errb)
+				return 0
+			}
+			c, err := compareValues(ka, kb)
+			firstErr = cmp.Or(firstErr, err)
+			return c
+		}
+		switch {
+		case less(a, b):
+			return -1
+		case less(b, a):
+			return 1
+		}
+		return 0
+	})
+	return firstErr
+}
+
+// compareValues orders two values of the same type; false sorts before true.
+func compareValues(x, y any) (int, error) {
+	switch x := x.(type) {
+	case string:
+		if y, ok := y.(string); ok {
+			return strings.Compare(x, y), nil
+		}
+	case float64:
+		if y, ok := y.(float64); ok {
+			switch {
+			case x < y:
+				return -1, nil
+			case x > y:
+				return 1, nil
+			}
+			return 0, nil
+		}
+	case bool:
+		if y, ok := y.(bool); ok {
+			switch {
+			case x == y:
+				return 0, nil
+			case y:
+				return -1, nil
+			}
+			return 1, nil
+		}
+	}
+	return 0, fmt.Errorf("can't compare %v with %v", x, y)
+}
+
+type evalFunc func(a, b *github.Notification) (any, error)
+
+// exprParser is a recursive descent parser over the tokens of one
+// expression, building closures as it goes.
+type exprParser struct {
+	src    string
+	tokens []string
+	pos    int
+	// bare and pair record which kinds of field reference were used.
+	bare, pair bool
+}
+
+func (p *exprParser) lex() error {
+	s := p.src
+	for i := 0; i < len(s); {
+		c := rune(s[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '"' || c == '\'':
+			end := i + 1
+			for end < len(s) && s[end] != s[i] {
+				if s[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(s) {
+				return errors.New("unterminated string")
+			}
+			p.tokens = append(p.tokens, s[i:end+1])
+			i = end + 1
+		case unicode.IsDigit(c):
+			end := i
+			for end < len(s) && (unicode.IsDigit(rune(s[end])) || s[end] == '.') {
+				end++
+			}
+			p.tokens = append(p.tokens, s[i:end])
+			i = end
+		case c == '_' || unicode.IsLetter(c):
+			end := i
+			for end < len(s) && (s[end] == '_' || s[end] == '.' || unicode.IsLetter(rune(s[end])) || unicode.IsDigit(rune(s[end]))) {
+				end++
+			}
+			p.tokens = append(p.tokens, s[i:end])
+			i = end
+		default:
+			if two := s[i:min(i+2, len(s))]; slices.Contains([]string{"==", "!=", "<=", ">=", "&&", "||"}, two) {
+				p.tokens = append(p.tokens, two)
+				i += 2
+				continue
+			}
+			if !strings.ContainsRune("()?:!<>", c) {
+				return fmt.Errorf("unexpected %q", c)
+			}
+			p.tokens = append(p.tokens, string(c))
+			i++
+		}
+	}
+	return nil
+}
+
+func (p *exprParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *exprParser) expect(tok string) error {
+	if p.peek() != tok {
+		if p.peek() == "" {
+			return fmt.Errorf("expected %q at the end", tok)
+		}
+		return fmt.Errorf("expected %q, got %q", tok, p.peek())
+	}
+	p.pos++
+	return nil
+}
+
+func (p *exprParser) ternary() (evalFunc, error) {
+	cond, err := p.or()
+	if err != nil || p.peek() != "?" {
+		return cond, err
+	}
+	p.pos++
+	then, err := p.ternary()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	otherwise, err := p.ternary()
+	if err != nil {
+		return nil, err
+	}
+	return func(a, b *github.Notification) (any, error) {
+		c, err := evalBool(cond, a, b)
+		if err != nil {
+			return nil, err
+		}
+		if c {
+			return then(a, b)
+		}
+		return otherwise(a, b)
+	}, nil
+}
+
+func (p *exprParser) or() (evalFunc, error) {
+	return p.logical("||", p.and, true)
+}
+
+func (p *exprParser) and() (evalFunc, error) {
+	return p.logical("&&", p.comparison, false)
+}
+
+// logical parses a chain of op, which stops early once a side is stopAt.
+func (p *exprParser) logical(op string, next func() (evalFunc, error), stopAt bool) (evalFunc, error) {
+	left, err := next()
+	for err == nil && p.peek() == op {
+		p.pos++
+		var right evalFunc
+		if right, err = next(); err != nil {
+			break
+		}
+		l := left
+		left = func(a, b *github.Notification) (any, error) {
+			v, err := evalBool(l, a, b)
+			if err != nil || v == stopAt {
+				return v, err
+			}
+			return evalBool(right, a, b)
+		}
+	}
+	return left, err
+}
+
+func (p *exprParser) comparison() (evalFunc, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	op := p.peek()
+	if !slices.Contains([]string{"==", "!=", "<", "<=", ">", ">="}, op) {
+		return left, nil
+	}
+	p.pos++
+	right, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	return func(a, b *github.Notification) (any, error) {
+		x, err := left(a, b)
+		if err != nil {
+			return nil, err
+		}
+		y, err := right(a, b)
+		if err != nil {
+			return nil, err
+		}
+		c, err := compareValues(x, y)
+		if err != nil {
+			return nil, err
+		}
+		switch op {
+		case "==":
+			return c == 0, nil
+		case "!=":
+			return c != 0, nil
+		case "<":
+			return c < 0, nil
+		case "<=":
+			return c <= 0, nil
+		case ">":
+			return c > 0, nil
+		}
+		return c >= 0, nil
+	}, nil
+}
+
+func (p *exprParser) unary() (evalFunc, error) {
+	if p.peek() != "!" {
+		return p.primary()
+	}
+	p.pos++
+	operand, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	return func(a, b *github.Notification) (any, error) {
+		v, err := evalBool(operand, a, b)
+		return !v, err
+	}, nil
+}
+
+func (p *exprParser) primary() (evalFunc, error) {
+	tok := p.peek()
+	if tok == "" {
+		return nil, errors.New("unexpected end of expression")
+	}
+	p.pos++
+	switch {
+	case tok == "(":
+		inner, err := p.ternary()
+		if err != nil {
+			return nil, err
+		}
+		return inner, p.expect(")")
+	case tok[0] == '"' || tok[0] == '\'':
+		quoted := tok
+		if tok[0] == '\'' {
+			quoted = doubleQuoted(tok[1 : len(tok)-1])
+		}
+		s, err := strconv.Unquote(quoted)
+		if err != nil {
+			return nil, fmt.Errorf("bad string %s", tok)
+		}
+		return constant(s), nil
+	case unicode.IsDigit(rune(tok[0])):
+		f, err := strconv.ParseFloat(tok, 64)
+		if err != nil {
+			return nil, fmt.Errorf("bad number %s", tok)
+		}
+		return constant(f), nil
+	case tok == "true" || tok == "false":
+		return constant(tok == "true"), nil
+	}
+	return p.field(tok)
+}
+
+func (p *exprParser) field(tok string) (evalFunc, error) {
+	which, field, qualified := strings.Cut(tok, ".")
+	if !qualified {
+		which, field = "", tok
+	}
+	if !slices.Contains(sortFields, field) || (qualified && which != "a" && which != "b") {
+		return nil, fmt.Errorf("unknown field %q (fields: %s, bare or as a.field and b.field)", tok, strings.Join(sortFields, ", "))
+	}
+	if !qualified {
+		p.bare = true
+		// A key is worked out from one notification, passed as a.
+		which = "a"
+	} else {
+		p.pair = true
+	}
+	return func(a, b *github.Notification) (any, error) {
+		if which == "b" {
+			return fieldValue(b, field), nil
+		}
+		return fieldValue(a, field), nil
+	}, nil
+}
+
+// doubleQuoted turns the inside of a single-quoted string into the same
+// string double-quoted, for strconv.Unquote: \' becomes ' and " gets
+// escaped.
+func doubleQuoted(inner string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(inner); i++ {
+		switch c := inner[i]; {
+		case c == '\\' && i+1 < len(inner):
+			i++
+			if inner[i] != '\'' {
+				b.WriteByte('\\')
+			}
+			b.WriteByte(inner[i])
+		case c == '"':
+			b.WriteString(`\"`)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+func constant(v any) evalFunc {
+	return func(a, b *github.Notification) (any, error) { return v, nil }
+}
+
+func evalBool(f evalFunc, a, b *github.Notification) (bool, error) {
+	v, err := f(a, b)
+	if err != nil {
+		return false, err
+	}
+	c, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("%v is not true or false", v)
+	}
+	return c, nil
+}
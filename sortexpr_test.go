@@ -0,0 +1,164 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v66/github"
+	"github.com/lukemassa/github-notification-manager/testutil"
+)
+
+func sortExprNotification(id, repo, reason string, updated time.Time) *github.Notification {
+	n := testutil.NewNotification(id, repo, "Notification "+id, updated)
+	n.Reason = github.String(reason)
+	return n
+}
+
+func TestSortExprEval(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	a := sortExprNotification("1", "lukemassa/alpha", "mention", now)
+	b := sortExprNotification("2", "lukemassa/beta", "review_requested", now.Add(time.Hour))
+	tests := []struct {
+		expr string
+		want any
+	}{
+		// && binds tighter than ||.
+		{`true || false && false`, true},
+		{`false && false || true`, true},
+		{`(true || false) && false`, false},
+		{`!false && !true`, false},
+		{`!(false || true)`, false},
+		// ?: is right-associative and nests in either branch.
+		{`true ? false ? 1 : 2 : 3`, 2.0},
+		{`false ? 1 : true ? 2 : 3`, 2.0},
+		{`false ? 1 : false ? 2 : 3`, 3.0},
+		{`true || false ? "yes" : "no"`, "yes"},
+		// Both quote styles, with escapes.
+		{`"it's" == 'it\'s'`, true},
+		{`'say "hi"' == "say \"hi\""`, true},
+		{`'tab\there' == "tab	here"`, true},
+		{`'back\\slash'`, `back\slash`},
+		{`"review_requested" == 'review_requested'`, true},
+		// Comparisons of each type.
+		{`1 < 2 && 2 <= 2 && 3 > 2 && 3 >= 3 && 1 != 2`, true},
+		{`"a" < "b"`, true},
+		{`false < true`, true},
+		// Pair mode sees both notifications.
+		{`a.repo < b.repo`, true},
+		{`a.updated_at > b.updated_at`, false},
+		{`b.reason == "review_requested" && a.reason == "mention"`, true},
+		// Key mode sees the one notification, as a.
+		{`reason == "review_requested" ? 0 : 1`, 1.0},
+		{`id`, "1"},
+		{`updated_at`, float64(now.Unix())},
+	}
+	for _, tt := range tests {
+		e, err := parseSortExpr(tt.expr)
+		if err != nil {
+			t.Errorf("parseSortExpr(%s): %v", tt.expr, err)
+			continue
+		}
+		got, err := e.eval(a, b)
+		if err != nil {
+			t.Errorf("%s: %v", tt.expr, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s = %#v, want %#v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestSortExprSort(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	notifications := func() []*github.Notification {
+		return []*github.Notification{
+			sortExprNotification("1", "lukemassa/beta", "mention", now),
+			sortExprNotification("2", "lukemassa/alpha", "review_requested", now.Add(2*time.Hour)),
+			sortExprNotification("3", "lukemassa/beta", "subscribed", now.Add(time.Hour)),
+			sortExprNotification("4", "lukemassa/alpha", "review_requested", now.Add(-time.Hour)),
+		}
+	}
+	tests := []struct {
+		expr string
+		pair bool
+		want []string
+	}{
+		// Ties keep the order given.
+		{`reason == "review_requested" ? 0 : 1`, false, []string{"2", "4", "1", "3"}},
+		{`repo`, false, []string{"2", "4", "1", "3"}},
+		{`updated_at`, false, []string{"4", "1", "3", "2"}},
+		{`a.updated_at > b.updated_at`, true, []string{"2", "3", "1", "4"}},
+		{`a.repo > b.repo`, true, []string{"1", "3", "2", "4"}},
+		{`a.repo < b.repo || a.repo == b.repo && a.updated_at < b.updated_at`, true, []string{"4", "2", "1", "3"}},
+	}
+	for _, tt := range tests {
+		e, err := parseSortExpr(tt.expr)
+		if err != nil {
+			t.Errorf("parseSortExpr(%s): %v", tt.expr, err)
+			continue
+		}
+		if e.pair != tt.pair {
+			t.Errorf("%s: pair = %v, want %v", tt.expr, e.pair, tt.pair)
+		}
+		ns := notifications()
+		if err := e.sort(ns); err != nil {
+			t.Errorf("%s: %v", tt.expr, err)
+			continue
+		}
+		if got := notificationIDs(ns); !slices.Equal(got, tt.want) {
+			t.Errorf("%s sorted %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestSortExprMixedTypes(t *testing.T) {
+	// Only the values of real notifications give a number and a string to
+	// compare, so this gets past parseSortExpr and fails in sort.
+	e, err := parseSortExpr(`reason == "mention" ? 0 : "last"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	ns := []*github.Notification{
+		sortExprNotification("1", "lukemassa/alpha", "mention", now),
+		sortExprNotification("2", "lukemassa/alpha", "subscribed", now),
+	}
+	if err := e.sort(ns); err == nil || !strings.Contains(err.Error(), "can't compare") {
+		t.Errorf("sort = %v, want a can't compare error", err)
+	}
+}
+
+func TestParseSortExprErrors(t *testing.T) {
+	tests := []struct {
+		expr, want string
+	}{
+		{``, "unexpected end"},
+		{`reason ==`, "unexpected end"},
+		{`(true`, `expected ")"`},
+		{`true ? 1`, `expected ":"`},
+		{`true true`, `unexpected "true"`},
+		{`reason # "x"`, `unexpected '#'`},
+		{`"unterminated`, "unterminated string"},
+		{`'unterminated\'`, "unterminated string"},
+		{`1.2.3`, "bad number"},
+		{`"bad \q escape"`, "bad string"},
+		{`title_length`, "unknown field"},
+		{`c.reason == "mention"`, "unknown field"},
+		{`a.nope < b.nope`, "unknown field"},
+		{`reason == a.reason`, "not both"},
+		{`a.repo`, "must be true or false"},
+		{`1 == "1"`, "can't compare"},
+		{`true && "x"`, "not true or false"},
+		{`1 ? 2 : 3`, "not true or false"},
+		{`!"x"`, "not true or false"},
+	}
+	for _, tt := range tests {
+		_, err := parseSortExpr(tt.expr)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("parseSortExpr(%s) = %v, want an error containing %q", tt.expr, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// State is what we remember between runs. It lives in the user's config
+// directory as a small JSON file.
+type State struct {
+	// Snoozed maps thread IDs to when they should reappear.
+	Snoozed map[string]time.Time `json:"snoozed,omitempty"`
+	// History is what we've done to notifications, oldest first.
+	History []HistoryEntry `json:"history,omitempty"`
+	// InboxZero holds the local dates (YYYY-MM-DD) on which a run ended with
+	// nothing left, oldest first.
+	InboxZero []string `json:"inbox_zero,omitempty"`
+	// Notes are -annotate notes, by thread ID.
+	Notes map[string]string `json:"notes,omitempty"`
+}
+
+func statePath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "github-notification-manager", "state.json"), nil
+}
+
+// loadState reads the state file, returning an empty state if there isn't one yet.
+func loadState() (*State, error) {
+	state := &State{}
+	path, err := statePath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+func (s *State) save() error {
+	path, err := statePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
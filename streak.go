@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// maxInboxZeroDays caps how many inbox-zero dates the state file remembers.
+const maxInboxZeroDays = 400
+
+// reachedInboxZero records that the inbox hit zero today and returns the
+// current streak.
+func (s *State) reachedInboxZero(now time.Time) int {
+	today := now.Format(time.DateOnly)
+	if !slices.Contains(s.InboxZero, today) {
+		s.InboxZero = append(s.InboxZero, today)
+		slices.Sort(s.InboxZero)
+		if extra := len(s.InboxZero) - maxInboxZeroDays; extra > 0 {
+			s.InboxZero = s.InboxZero[extra:]
+		}
+	}
+	return s.streak(now)
+}
+
+// streak counts the consecutive days, ending today, on which the inbox hit
+// zero. Days are calendar dates in now's time zone; stepping back through
+// them as UTC dates keeps DST changes from skipping or repeating a day.
+func (s *State) streak(now time.Time) int {
+	y, m, d := now.Date()
+	day := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	n := 0
+	for slices.Contains(s.InboxZero, day.Format(time.DateOnly)) {
+		n++
+		day = day.AddDate(0, 0, -1)
+	}
+	return n
+}
+
+func printStreak(days int) {
+	if days > 1 {
+		fmt.Printf("🔥 %d days in a row at inbox zero.\n", days)
+	}
+}
+
+// clean reports whether the session left nothing behind: nothing skipped and
+// nothing failed. Snoozed notifications don't count, they're dealt with.
+func (s *session) clean() bool {
+	return s.skipped == 0 && s.failed == 0
+}
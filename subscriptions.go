@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"text/tabwriter"
+
+	"github.com/google/go-github/v66/github"
+)
+
+// runSubscriptions implements `subscriptions list|add|remove`, for managing
+// thread subscriptions in bulk without the web UI.
+func runSubscriptions(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: github-notification-manager subscriptions list|add|remove [flags] [notification-id...]")
+		return exitError
+	}
+	sub := args[0]
+	if sub != "list" && sub != "add" && sub != "remove" {
+		fmt.Fprintf(os.Stderr, "unknown subscriptions subcommand %q\n", sub)
+		return exitError
+	}
+
+	fs := flag.NewFlagSet("subscriptions "+sub, flag.ExitOnError)
+	var opts Options
+	opts.register(fs)
+	fs.Parse(args[1:])
+	if sub != "list" && fs.NArg() == 0 {
+		fmt.Fprintf(os.Stderr, "usage: github-notification-manager subscriptions %s [flags] <notification-id>...\n", sub)
+		return exitError
+	}
+
+	settings, err := opts.resolve()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitStatus(err)
+	}
+	if settings.Token == "" {
+		fmt.Fprintln(os.Stderr, "❌ GITHUB_TOKEN environment variable (or a token in the config) is required")
+		return exitAuth
+	}
+	ctx := context.Background()
+	client, _, err := newClient(ctx, settings)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return exitStatus(err)
+	}
+
+	switch sub {
+	case "list":
+		return listSubscriptions(ctx, client, settings)
+	case "add":
+		return eachThread(fs.Args(), "🔔 Subscribed to", func(id string) error {
+			_, _, err := client.Activity.SetThreadSubscription(ctx, id, &github.Subscription{Subscribed: github.Bool(true), Ignored: github.Bool(false)})
+			return err
+		})
+	default:
+		return eachThread(fs.Args(), "🔕 Removed subscription to", func(id string) error {
+			_, err := client.Activity.DeleteThreadSubscription(ctx, id)
+			return err
+		})
+	}
+}
+
+// eachThread runs fn on each thread ID, reporting as it goes. It keeps going
+// past failures and returns the exit status for the first, if there were
+// any.
+func eachThread(ids []string, done string, fn func(id string) error) int {
+	status := exitOK
+	for _, id := range ids {
+		if err := fn(id); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %s: %v\n", id, err)
+			if status == exitOK {
+				status = exitStatus(err)
+			}
+			continue
+		}
+		fmt.Printf("%s %s\n", done, id)
+	}
+	return status
+}
+
+// listSubscriptions shows the subscription for every unread notification the
+// settings select. That's one request per notification.
+func listSubscriptions(ctx context.Context, client *github.Client, settings *Settings) int {
+	notifications, _, err := fetchAllUnread(ctx, client, settings.Repos, settings.fetchOptions())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ fetching notifications: %v\n", err)
+		return exitStatus(err)
+	}
+	notifications = applyFilters(notifications, settings)
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tSubscription\tRepo\tTitle")
+	status := exitOK
+	for _, n := range notifications {
+		kind, err := subscriptionKind(ctx, client, n.GetID())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %s: %v\n", n.GetID(), err)
+			if status == exitOK {
+				status = exitStatus(err)
+			}
+			continue
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", n.GetID(), kind, n.GetRepository().GetFullName(), n.GetSubject().GetTitle())
+	}
+	tw.Flush()
+	return status
+}
+
+// subscriptionKind describes the thread subscription: "subscribed",
+// "ignored", or "none" when there isn't one and notifications come from
+// watching the repo or participating.
+func subscriptionKind(ctx context.Context, client *github.Client, id string) (string, error) {
+	sub, resp, err := client.Activity.GetThreadSubscription(ctx, id)
+	switch {
+	case resp != nil && resp.StatusCode == http.StatusNotFound:
+		return "none", nil
+	case err != nil:
+		return "", err
+	case sub.GetIgnored():
+		return "ignored", nil
+	case sub.GetSubscribed():
+		return "subscribed", nil
+	default:
+		return "none", nil
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v66/github"
+)
+
+// decision is what happens to a notification without asking the user.
+type decision struct {
+	// Action is "mark-read", "skip", or empty to ask.
+	Action string
+	// Why explains the action for display, e.g. "rule: renovate".
+	Why string
+	// Source is the short name of what decided, e.g. "renovate".
+	Source string
+}
+
+// decide works out whether n is handled automatically. Rules win over the
+// per-type skips, which win over the stale handling.
+func (s *Settings) decide(n *github.Notification, now time.Time) decision {
+	d := s.details[n.GetID()]
+	if rule := matchRule(s.Rules, n, d); rule != nil {
+		return decision{Action: rule.Action, Why: "rule: " + rule.Name, Source: rule.Name}
+	}
+	switch t := n.GetSubject().GetType(); {
+	case t == "Release" && s.SkipReleases:
+		return decision{Action: "skip", Why: "release", Source: "release"}
+	case t == "Release" && s.AutoReadReleases:
+		return decision{Action: "mark-read", Why: "release", Source: "release"}
+	case t == "CheckSuite" && s.SkipCheckSuites:
+		return decision{Action: "skip", Why: "check suite", Source: "check suite"}
+	case d != nil && d.closed() && s.IgnoreClosed:
+		return decision{Action: "skip", Why: "closed", Source: "closed"}
+	case d != nil && d.Draft && t == "PullRequest" && s.IgnoreDrafts:
+		return decision{Action: "skip", Why: "draft", Source: "draft"}
+	case d != nil && d.Draft && t == "PullRequest" && s.AutoReadDrafts:
+		return decision{Action: "mark-read", Why: "draft", Source: "draft"}
+	}
+	if s.stale(n, now) {
+		if s.AutoReadStale {
+			return decision{Action: "mark-read", Why: "🕸️ stale", Source: "stale"}
+		}
+		if s.SkipStale {
+			return decision{Action: "skip", Why: "🕸️ stale", Source: "stale"}
+		}
+	}
+	return decision{}
+}
+
+// summaryTypes are the subject types broken out in the summary; everything
+// else is counted as "Other".
+var summaryTypes = []struct {
+	subjectType string
+	label       string
+}{
+	{"PullRequest", "PRs"},
+	{"Issue", "Issues"},
+	{"Release", "Releases"},
+	{"Discussion", "Discussions"},
+}
+
+// printSummary gives a sense of what the interactive session is about to
+// involve before it starts.
+func printSummary(notifications []*github.Notification, fetched, pages int, settings *Settings) {
+	fmt.Println(summaryLine(notifications, fetched, pages, settings, time.Now()))
+}
+
+// summaryLine is printSummary's line. fetched is how many notifications the
+// API returned, before filtering down to notifications.
+func summaryLine(notifications []*github.Notification, fetched, pages int, settings *Settings, now time.Time) string {
+	byType := map[string]int{}
+	auto, skipped := 0, 0
+	for _, n := range notifications {
+		byType[n.GetSubject().GetType()]++
+		switch settings.decide(n, now).Action {
+		case "mark-read":
+			auto++
+		case "skip":
+			skipped++
+		}
+	}
+
+	var parts []string
+	other := len(notifications)
+	for _, t := range summaryTypes {
+		if c := byType[t.subjectType]; c > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", c, t.label))
+			other -= c
+		}
+	}
+	if other > 0 {
+		parts = append(parts, fmt.Sprintf("%d Other", other))
+	}
+
+	line := fmt.Sprintf("📬 Fetched %d notifications (%d page(s))", fetched, pages)
+	if fetched != len(notifications) {
+		line += fmt.Sprintf(", %d after filters", len(notifications))
+	}
+	line += fmt.Sprintf(": %s. Auto-approving %d.", strings.Join(parts, ", "), auto)
+	if skipped > 0 {
+		line += fmt.Sprintf(" Skipping %d.", skipped)
+	}
+	return line + fmt.Sprintf(" Presenting %d.", len(notifications)-auto-skipped)
+}
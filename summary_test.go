@@ -0,0 +1,35 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v66/github"
+	"github.com/lukemassa/github-notification-manager/testutil"
+)
+
+func TestSummaryLine(t *testing.T) {
+	now := time.Now()
+	release := testutil.NewNotification("3", "lukemassa/example", "v1.2.0", now)
+	release.Subject.Type = github.String("Release")
+	notifications := []*github.Notification{
+		testutil.NewNotification("1", "lukemassa/example", "chore(deps): update golang.org/x/term", now),
+		testutil.NewNotification("2", "lukemassa/example", "Add a -since flag", now),
+		release,
+	}
+	settings := &Settings{Rules: defaultRules(), SkipReleases: true}
+
+	tests := []struct {
+		name    string
+		fetched int
+		want    string
+	}{
+		{"unfiltered", 3, "📬 Fetched 3 notifications (1 page(s)): 2 PRs, 1 Releases. Auto-approving 1. Skipping 1. Presenting 1."},
+		{"filtered", 5, "📬 Fetched 5 notifications (1 page(s)), 3 after filters: 2 PRs, 1 Releases. Auto-approving 1. Skipping 1. Presenting 1."},
+	}
+	for _, tt := range tests {
+		if got := summaryLine(notifications, tt.fetched, 1, settings, now); got != tt.want {
+			t.Errorf("%s:\n got %s\nwant %s", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import "sync"
+
+// terminalRestorers undo whatever modes have done to the terminal (raw
+// input, a status line, a full-screen view), so a panic doesn't leave the
+// shell garbled. Modes register one while they're active.
+var terminalRestorers struct {
+	sync.Mutex
+	next int
+	fns  map[int]func()
+}
+
+// onTerminalRestore registers restore to run if we panic, and returns a
+// func that unregisters it for when the mode ends normally.
+func onTerminalRestore(restore func()) (unregister func()) {
+	terminalRestorers.Lock()
+	defer terminalRestorers.Unlock()
+	if terminalRestorers.fns == nil {
+		terminalRestorers.fns = map[int]func(){}
+	}
+	id := terminalRestorers.next
+	terminalRestorers.next++
+	terminalRestorers.fns[id] = restore
+	return func() {
+		terminalRestorers.Lock()
+		defer terminalRestorers.Unlock()
+		delete(terminalRestorers.fns, id)
+	}
+}
+
+// restoreTerminal runs the registered restorers, newest first, each at most
+// once.
+func restoreTerminal() {
+	terminalRestorers.Lock()
+	defer terminalRestorers.Unlock()
+	for id := terminalRestorers.next - 1; id >= 0; id-- {
+		if restore, ok := terminalRestorers.fns[id]; ok {
+			delete(terminalRestorers.fns, id)
+			restore()
+		}
+	}
+}
+
+// restoreTerminalOnPanic is deferred at the top of main. It puts the
+// terminal back and re-panics, so the crash is still reported in full. Only
+// panics that unwind through main pass here; one in another goroutine
+// still kills the process without it.
+func restoreTerminalOnPanic() {
+	if r := recover(); r != nil {
+		restoreTerminal()
+		panic(r)
+	}
+}
@@ -0,0 +1,306 @@
+// Package testutil has helpers shared by the tests, chiefly a fake GitHub
+// API for the notification endpoints.
+package testutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v66/github"
+)
+
+// MockGitHubServer serves canned notifications the way GitHub does: per
+// repo, unread only unless all=true, paginated with a Link header, and with
+// Last-Modified so conditional requests can get a 304. Repo names are
+// matched case-insensitively, as GitHub does. It also answers for the
+// authenticated user and lists open pull requests by head branch.
+type MockGitHubServer struct {
+	// PageSize caps how many notifications a listing page returns, whatever
+	// per_page asks for, so tests can paginate without making hundreds.
+	PageSize int
+	// AcceptRepoReads makes marking a whole repo read answer 202 Accepted,
+	// as GitHub does when it finishes the job in the background, instead of
+	// 205.
+	AcceptRepoReads bool
+	// Login is who the authenticated user is.
+	Login string
+
+	srv *httptest.Server
+
+	mu         sync.Mutex
+	repos      map[string]*mockRepo
+	markedRead map[string]bool
+	failPage   map[failKey]int
+	failMark   map[string]int
+	ignored    map[string]bool
+}
+
+type mockRepo struct {
+	notifications []*github.Notification
+	pulls         []*github.PullRequest
+	// modified is bumped on every change; it's a clock of its own so
+	// Last-Modified moves even when a test changes things within a second.
+	modified time.Time
+	// status, if set, is what every listing request answers instead.
+	status int
+}
+
+type failKey struct {
+	repo string
+	page int
+}
+
+// NewMockGitHubServer starts a server that's closed when the test ends.
+func NewMockGitHubServer(t testing.TB) *MockGitHubServer {
+	m := &MockGitHubServer{
+		repos:      map[string]*mockRepo{},
+		markedRead: map[string]bool{},
+		failPage:   map[failKey]int{},
+		failMark:   map[string]int{},
+		ignored:    map[string]bool{},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/{owner}/{repo}/notifications", m.handleList)
+	mux.HandleFunc("PUT /repos/{owner}/{repo}/notifications", m.handleMarkRepoRead)
+	mux.HandleFunc("PATCH /notifications/threads/{id}", m.handleMarkRead)
+	mux.HandleFunc("PUT /notifications/threads/{id}/subscription", m.handleSubscribe)
+	mux.HandleFunc("GET /user", m.handleUser)
+	mux.HandleFunc("GET /repos/{owner}/{repo}/pulls", m.handleListPulls)
+	m.srv = httptest.NewServer(mux)
+	t.Cleanup(m.srv.Close)
+	return m
+}
+
+// Client returns a GitHub client that talks to the server.
+func (m *MockGitHubServer) Client() *github.Client {
+	return m.ClientWithTransport(nil)
+}
+
+// ClientWithTransport is Client with wrap's transport in front of the
+// server's, for testing transports.
+func (m *MockGitHubServer) ClientWithTransport(wrap func(base http.RoundTripper) http.RoundTripper) *github.Client {
+	hc := m.srv.Client()
+	if wrap != nil {
+		hc.Transport = wrap(hc.Transport)
+	}
+	client := github.NewClient(hc)
+	client.BaseURL, _ = url.Parse(m.srv.URL + "/")
+	return client
+}
+
+// AddNotification adds n to the listing for its repository, which must be
+// set.
+func (m *MockGitHubServer) AddNotification(n *github.Notification) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r := m.repo(n.GetRepository().GetFullName())
+	r.notifications = append(r.notifications, n)
+	r.touch()
+}
+
+// AddPullRequest adds an open pull request to repo. Its head branch is
+// pr.Head.Ref.
+func (m *MockGitHubServer) AddPullRequest(repo string, pr *github.PullRequest) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r := m.repo(repo)
+	r.pulls = append(r.pulls, pr)
+}
+
+// MarkReadCalled reports whether the thread was marked read, on its own or
+// with the rest of its repo.
+func (m *MockGitHubServer) MarkReadCalled(id string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.markedRead[id]
+}
+
+// Ignored reports whether the thread was unsubscribed from.
+func (m *MockGitHubServer) Ignored(id string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.ignored[id]
+}
+
+// FailMarkRead makes marking the thread read answer status, or succeed
+// again if status is 0.
+func (m *MockGitHubServer) FailMarkRead(id string, status int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.failMark[id] = status
+}
+
+// FailRepo makes every listing request for repo answer status, e.g. 404 for
+// a repo that's been deleted.
+func (m *MockGitHubServer) FailRepo(repo string, status int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.repo(repo).status = status
+}
+
+// FailPage makes one page of repo's listing answer status.
+func (m *MockGitHubServer) FailPage(repo string, page, status int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.failPage[failKey{strings.ToLower(repo), page}] = status
+}
+
+// NewNotification builds an unread pull request notification in repo.
+func NewNotification(id, repo, title string, updated time.Time) *github.Notification {
+	return &github.Notification{
+		ID:         github.String(id),
+		Repository: &github.Repository{FullName: github.String(repo)},
+		Subject: &github.NotificationSubject{
+			Title: github.String(title),
+			URL:   github.String("https://api.github.com/repos/" + repo + "/pulls/" + id),
+			Type:  github.String("PullRequest"),
+		},
+		Reason:    github.String("subscribed"),
+		Unread:    github.Bool(true),
+		UpdatedAt: &github.Timestamp{Time: updated},
+	}
+}
+
+// repo returns the named repo, creating it. m.mu must be held.
+func (m *MockGitHubServer) repo(fullName string) *mockRepo {
+	key := strings.ToLower(fullName)
+	r, ok := m.repos[key]
+	if !ok {
+		r = &mockRepo{modified: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+		m.repos[key] = r
+	}
+	return r
+}
+
+func (r *mockRepo) touch() { r.modified = r.modified.Add(time.Second) }
+
+func (m *MockGitHubServer) handleList(w http.ResponseWriter, req *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	name := strings.ToLower(req.PathValue("owner") + "/" + req.PathValue("repo"))
+	r, ok := m.repos[name]
+	if !ok {
+		r = &mockRepo{}
+	}
+	q := req.URL.Query()
+	page, _ := strconv.Atoi(q.Get("page"))
+	page = max(page, 1)
+	if r.status != 0 {
+		http.Error(w, `{"message":"mock failure"}`, r.status)
+		return
+	}
+	if status := m.failPage[failKey{name, page}]; status != 0 {
+		http.Error(w, `{"message":"mock failure"}`, status)
+		return
+	}
+	if since, err := http.ParseTime(req.Header.Get("If-Modified-Since")); err == nil && page == 1 && !r.modified.After(since) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	var listed []*github.Notification
+	cutoff, _ := time.Parse(time.RFC3339, q.Get("since"))
+	for _, n := range r.notifications {
+		if (n.GetUnread() || q.Get("all") == "true") && !n.GetUpdatedAt().Before(cutoff) {
+			listed = append(listed, n)
+		}
+	}
+	perPage, _ := strconv.Atoi(q.Get("per_page"))
+	if perPage <= 0 {
+		perPage = 30
+	}
+	if m.PageSize > 0 {
+		perPage = min(perPage, m.PageSize)
+	}
+	start := min((page-1)*perPage, len(listed))
+	end := min(start+perPage, len(listed))
+	if end < len(listed) {
+		q.Set("page", strconv.Itoa(page+1))
+		next := url.URL{Scheme: "http", Host: req.Host, Path: req.URL.Path, RawQuery: q.Encode()}
+		w.Header().Set("Link", fmt.Sprintf("<%s>; rel=\"next\"", next.String()))
+	}
+	w.Header().Set("Last-Modified", r.modified.Format(http.TimeFormat))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(listed[start:end])
+}
+
+func (m *MockGitHubServer) handleMarkRead(w http.ResponseWriter, req *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	id := req.PathValue("id")
+	if status := m.failMark[id]; status != 0 {
+		http.Error(w, `{"message":"mock failure"}`, status)
+		return
+	}
+	m.markedRead[id] = true
+	for _, r := range m.repos {
+		for _, n := range r.notifications {
+			if n.GetID() == id {
+				n.Unread = github.Bool(false)
+				r.touch()
+			}
+		}
+	}
+	w.WriteHeader(http.StatusResetContent)
+}
+
+func (m *MockGitHubServer) handleSubscribe(w http.ResponseWriter, req *http.Request) {
+	var sub github.Subscription
+	if err := json.NewDecoder(req.Body).Decode(&sub); err != nil {
+		http.Error(w, `{"message":"bad subscription"}`, http.StatusBadRequest)
+		return
+	}
+	m.mu.Lock()
+	m.ignored[req.PathValue("id")] = sub.GetIgnored()
+	m.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(&sub)
+}
+
+func (m *MockGitHubServer) handleMarkRepoRead(w http.ResponseWriter, req *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r := m.repo(req.PathValue("owner") + "/" + req.PathValue("repo"))
+	for _, n := range r.notifications {
+		m.markedRead[n.GetID()] = true
+		n.Unread = github.Bool(false)
+	}
+	r.touch()
+	if m.AcceptRepoReads {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	w.WriteHeader(http.StatusResetContent)
+}
+
+func (m *MockGitHubServer) handleUser(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(&github.User{Login: github.String(m.Login)})
+}
+
+// handleListPulls only knows about open pull requests, and filters by head
+// the way GitHub does, as "owner:branch".
+func (m *MockGitHubServer) handleListPulls(w http.ResponseWriter, req *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	owner := req.PathValue("owner")
+	listed := []*github.PullRequest{}
+	if r, ok := m.repos[strings.ToLower(owner+"/"+req.PathValue("repo"))]; ok {
+		head := req.URL.Query().Get("head")
+		for _, pr := range r.pulls {
+			if head == "" || strings.EqualFold(head, owner+":"+pr.GetHead().GetRef()) {
+				listed = append(listed, pr)
+			}
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(listed)
+}
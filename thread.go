@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/go-github/v66/github"
+)
+
+// printThreadInfo fetches and prints what GitHub knows about the thread
+// beyond the notification itself: when it was last read and whether we're
+// subscribed. Failures are logged and leave the prompt usable.
+func (d *display) printThreadInfo(ctx context.Context, client *github.Client, n *github.Notification) {
+	thread, _, err := client.Activity.GetThread(ctx, n.GetID())
+	if err != nil {
+		log.Printf("⚠️  Failed to fetch thread info: %v\n", err)
+		return
+	}
+	now := time.Now()
+	lastRead := "never"
+	if t := thread.GetLastReadAt(); !t.IsZero() {
+		lastRead = formatTime(t.Time, now, d.timeFormat)
+	}
+	fmt.Printf("ℹ️  Reason: %s\n", thread.GetReason())
+	fmt.Printf("   Last read: %s\n", lastRead)
+	fmt.Printf("   Updated: %s\n", formatTime(thread.GetUpdatedAt().Time, now, d.timeFormat))
+
+	// 404 means no explicit subscription: we're only getting it because we're
+	// watching the repo or participating.
+	sub, resp, err := client.Activity.GetThreadSubscription(ctx, n.GetID())
+	switch {
+	case resp != nil && resp.StatusCode == http.StatusNotFound:
+		fmt.Println("   Subscription: none (watching the repo or participating)")
+	case err != nil:
+		log.Printf("⚠️  Failed to fetch subscription: %v\n", err)
+	case sub.GetIgnored():
+		fmt.Println("   Subscription: ignored")
+	case sub.GetSubscribed():
+		fmt.Printf("   Subscription: subscribed since %s\n", formatTime(sub.GetCreatedAt().Time, now, d.timeFormat))
+	default:
+		fmt.Println("   Subscription: not subscribed")
+	}
+}
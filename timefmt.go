@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hako/durafmt"
+)
+
+// timeFormats are the named values -time-format accepts. Anything else is
+// taken as a Go time layout.
+var timeFormats = []string{"relative", "absolute", "short-relative", "human", "rfc3339", "kitchen"}
+
+// timeLayouts are the named formats that are just a layout.
+var timeLayouts = map[string]string{
+	"absolute": time.RFC3339,
+	"rfc3339":  time.RFC3339,
+	"kitchen":  time.Kitchen,
+}
+
+// checkTimeFormat returns format if it's a named format or a usable Go
+// layout (one with at least one element of the reference time in it), and
+// otherwise warns and falls back to relative.
+func checkTimeFormat(format string) string {
+	if slices.Contains(timeFormats, format) {
+		return format
+	}
+	reference := time.Date(2006, time.January, 2, 15, 4, 5, 0, time.UTC)
+	probe := time.Date(2001, time.March, 4, 7, 8, 9, 0, time.UTC)
+	if probe.Format(format) == reference.Format(format) {
+		log.Printf("⚠️  -time-format %q is neither a known format (%s) nor a Go time layout; using relative\n",
+			format, strings.Join(timeFormats, ", "))
+		return "relative"
+	}
+	return format
+}
+
+// formatTime renders t for display, relative to now where the format calls
+// for it. format is a name from timeFormats or a Go layout.
+func formatTime(t, now time.Time, format string) string {
+	if layout, ok := timeLayouts[format]; ok {
+		return t.Format(layout)
+	}
+	switch format {
+	case "relative":
+		return durafmt.Parse(now.Sub(t)).LimitFirstN(2).String() + " ago"
+	case "short-relative":
+		return shortDuration(now.Sub(t)) + " ago"
+	case "human":
+		return humanTime(t.Local(), now.Local())
+	default:
+		return t.Local().Format(format)
+	}
+}
+
+// shortDuration renders d in its largest whole unit, e.g. "2h" or "3d".
+func shortDuration(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	case d < 7*24*time.Hour:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	case d < 365*24*time.Hour:
+		return fmt.Sprintf("%dw", int(d.Hours()/24/7))
+	default:
+		return fmt.Sprintf("%dy", int(d.Hours()/24/365))
+	}
+}
+
+// humanTime renders t the way you'd say it, e.g. "yesterday at 3:42 PM".
+func humanTime(t, now time.Time) string {
+	clock := t.Format(time.Kitchen)
+	days := daysBetween(t, now)
+	switch {
+	case days == 0:
+		return "today at " + clock
+	case days == 1:
+		return "yesterday at " + clock
+	case days > 1 && days < 7:
+		return t.Format("Monday") + " at " + clock
+	case t.Year() == now.Year():
+		return t.Format("Jan 2") + " at " + clock
+	default:
+		return t.Format("Jan 2, 2006")
+	}
+}
+
+// daysBetween counts calendar days from t to now in now's location, so
+// it's right across DST changes.
+func daysBetween(t, now time.Time) int {
+	y1, m1, d1 := t.In(now.Location()).Date()
+	y2, m2, d2 := now.Date()
+	start := time.Date(y1, m1, d1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(y2, m2, d2, 0, 0, 0, 0, time.UTC)
+	return int(end.Sub(start).Hours() / 24)
+}
+
+// Age is a duration flag that also accepts days and weeks, e.g. "7d" or
+// "2w", since that's how people think about notification ages.
+type Age time.Duration
+
+func (a *Age) String() string {
+	d := time.Duration(*a)
+	switch {
+	case d == 0:
+		return "0"
+	case d%(7*24*time.Hour) == 0:
+		return fmt.Sprintf("%dw", d/(7*24*time.Hour))
+	case d%(24*time.Hour) == 0:
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	default:
+		return d.String()
+	}
+}
+
+func (a *Age) Set(s string) error {
+	d, err := parseAge(s)
+	if err != nil {
+		return err
+	}
+	*a = Age(d)
+	return nil
+}
+
+func (a Age) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// parseAge is time.ParseDuration plus a "d" (day) and "w" (week) suffix.
+func parseAge(s string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			v, err := strconv.ParseFloat(n, 64)
+			if err != nil || v < 0 {
+				return 0, fmt.Errorf("invalid age %q", s)
+			}
+			return time.Duration(v * float64(unit)), nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid age %q", s)
+	}
+	return d, nil
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/google/go-github/v66/github"
+)
+
+// appendTodo appends a markdown checkbox line per notification to path, in
+// the order given, skipping any whose URL is already in the file. It returns how
+// many were added and how many were already there.
+func appendTodo(path string, notifications []*github.Notification) (added, existing int, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return 0, 0, err
+	}
+	content := string(data)
+
+	var b strings.Builder
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		b.WriteString("\n")
+	}
+	for _, n := range notifications {
+		s := summarize(n)
+		// Match the URL as link target so a prefix (issues/1 vs issues/12)
+		// doesn't count.
+		if strings.Contains(content, "("+s.URL+")") {
+			existing++
+			continue
+		}
+		content += "(" + s.URL + ")" // so duplicates within this run are caught too
+		fmt.Fprintf(&b, "- [ ] [%s](%s) — %s\n", markdownEscape(s.Title), s.URL, s.Repo)
+		added++
+	}
+	if added == 0 {
+		return 0, existing, nil
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return 0, existing, err
+	}
+	if _, err := f.WriteString(b.String()); err != nil {
+		f.Close()
+		return 0, existing, err
+	}
+	return added, existing, f.Close()
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v66/github"
+	"golang.org/x/term"
+)
+
+// watcher polls for notifications and reports new ones as they arrive. On a
+// terminal it keeps a single status line at the bottom; otherwise it logs a
+// line per poll.
+type watcher struct {
+	client   *github.Client
+	settings *Settings
+	disp     *display
+	interval time.Duration
+	beep     bool
+	enricher *enricher
+
+	tty    bool
+	seen   map[string]time.Time
+	status string
+	// listings are the last poll's, replayed so unchanged repos come back
+	// as 304s and keep what they had.
+	listings map[string]*listing
+}
+
+func (w *watcher) run(ctx context.Context) {
+	w.tty = term.IsTerminal(int(os.Stdout.Fd()))
+	w.seen = map[string]time.Time{}
+	w.enricher = newEnricher(w.client)
+	if w.tty {
+		defer onTerminalRestore(func() { fmt.Print("\r\033[K\n") })()
+	}
+
+	resize := make(chan os.Signal, 1)
+	if sigs := resizeSignals(); len(sigs) > 0 {
+		signal.Notify(resize, sigs...)
+		defer signal.Stop(resize)
+	}
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	count := 0
+	for {
+		count = w.poll(ctx, count)
+		select {
+		case <-ctx.Done():
+			if w.tty {
+				fmt.Println()
+			}
+			return
+		case <-resize:
+			w.drawStatus()
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll fetches once, prints anything new and returns the unread count. A
+// repo that answers 304 still counts what it had last poll, and a failed
+// poll leaves the count as it was.
+func (w *watcher) poll(ctx context.Context, count int) int {
+	fo := w.settings.fetchOptions()
+	fo.Previous = w.listings
+	notifications, fetched, err := fetchAllUnread(ctx, w.client, w.settings.Repos, fo)
+	switch {
+	case err != nil:
+		w.clearStatus()
+		log.Printf("⚠️  Failed to fetch notifications: %v\n", err)
+	case fetched.unchanged > 0 && fetched.unchanged == len(w.settings.Repos):
+	default:
+		w.listings = fetched.listings
+		notifications = applyFilters(notifications, w.settings)
+		notifications = applyEnrichedFilters(ctx, w.enricher, notifications, w.settings)
+		if err := w.enricher.save(); err != nil {
+			log.Printf("⚠️  Failed to save enrichment cache: %v\n", err)
+		}
+		var fresh []*github.Notification
+		for _, n := range notifications {
+			updated := n.GetUpdatedAt().Time
+			if last, ok := w.seen[n.GetID()]; !ok || updated.After(last) {
+				fresh = append(fresh, n)
+			}
+			w.seen[n.GetID()] = updated
+		}
+		count = len(notifications)
+		w.clearStatus()
+		for _, n := range fresh {
+			w.disp.printSeparator()
+			w.disp.printNotification(n)
+		}
+		if len(fresh) > 0 && w.beep {
+			fmt.Print("\a")
+		}
+	}
+
+	w.status = fmt.Sprintf("last checked %s — %d unread", time.Now().Format("15:04:05"), count)
+	w.drawStatus()
+	return count
+}
+
+func (w *watcher) drawStatus() {
+	if !w.tty {
+		fmt.Println(w.status)
+		return
+	}
+	status := w.status
+	if width, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && width > 1 && len([]rune(status)) >= width {
+		status = string([]rune(status)[:width-1])
+	}
+	fmt.Print("\r\033[K" + status)
+}
+
+// clearStatus wipes the status line so normal output can take its place.
+func (w *watcher) clearStatus() {
+	if w.tty && w.status != "" {
+		fmt.Print("\r\033[K")
+	}
+}
+
+// runWatch is the -watch entry point. Ctrl-C is how you stop watching, so
+// it isn't an error.
+func runWatch(ctx context.Context, w *watcher) int {
+	if w.interval <= 0 {
+		log.Print("-interval must be positive")
+		return exitError
+	}
+	fmt.Printf("👀 Watching %s every %s (Ctrl-C to stop)\n", strings.Join(w.settings.Repos, ", "), w.interval)
+	w.run(ctx)
+	return exitOK
+}
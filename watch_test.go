@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/lukemassa/github-notification-manager/testutil"
+)
+
+func TestWatchCountsUnchangedRepos(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	server := testutil.NewMockGitHubServer(t)
+	addNotifications(server, "lukemassa/example", "a", 2)
+	addNotifications(server, "lukemassa/other", "b", 1)
+	client := server.ClientWithTransport(func(base http.RoundTripper) http.RoundTripper {
+		return &conditionalTransport{base: base}
+	})
+	settings := &Settings{Repos: []string{"lukemassa/example", "lukemassa/other"}, Header: defaultHeader, Detail: defaultDetail}
+	disp, err := newDisplay(settings, "relative")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := &watcher{client: client, settings: settings, disp: disp, seen: map[string]time.Time{}, enricher: newEnricher(client)}
+
+	ctx := context.Background()
+	count := w.poll(ctx, 0)
+	if count != 3 {
+		t.Fatalf("first poll counted %d, want 3", count)
+	}
+	// lukemassa/example answers 304 from here on.
+	server.AddNotification(testutil.NewNotification("b2", "lukemassa/other", "b2", time.Now()))
+	if count = w.poll(ctx, count); count != 4 {
+		t.Errorf("poll with one repo unchanged counted %d, want 4", count)
+	}
+	if count = w.poll(ctx, count); count != 4 {
+		t.Errorf("poll with nothing changed counted %d, want 4", count)
+	}
+	server.FailRepo("lukemassa/other", http.StatusBadGateway)
+	if count = w.poll(ctx, count); count != 4 {
+		t.Errorf("failed poll counted %d, want 4", count)
+	}
+}